@@ -0,0 +1,349 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ApplyChange applies the specified change to a local base value, so that a
+// target document can be reconstructed from a base plus the diffs returned
+// by GetDiff without another fetch.
+//
+// The base of an ApplyJSONPatch or UpsertJSON change is a decoded JSON value
+// and the base of an ApplyTextPatch change is a string. Remove returns nil.
+func ApplyChange(base interface{}, change *Change) (interface{}, error) {
+	if change == nil {
+		return nil, fmt.Errorf("centraldogma: change should not be nil")
+	}
+	switch change.Type {
+	case UpsertJSON:
+		return normalizeJSONValue(change.Content)
+	case Remove:
+		return nil, nil
+	case ApplyJSONPatch:
+		return applyJSONPatch(base, change.Content)
+	case ApplyTextPatch:
+		text, ok := base.(string)
+		if !ok {
+			return nil, fmt.Errorf("centraldogma: base of a text patch should be a string, not %T", base)
+		}
+		patch, ok := change.Content.(string)
+		if !ok {
+			return nil, fmt.Errorf("centraldogma: content of a text patch should be a string, not %T",
+				change.Content)
+		}
+		return applyTextPatch(text, patch)
+	default:
+		return nil, fmt.Errorf("centraldogma: cannot apply a change of type %v locally", change.Type)
+	}
+}
+
+// normalizeJSONValue round-trips the specified value through encoding/json
+// so that callers always get the plain decoded form regardless of whether
+// the content arrived as json.RawMessage or an already decoded value.
+func normalizeJSONValue(content interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// jsonPatchOp is a single RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+func applyJSONPatch(base, content interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(encoded, &ops); err != nil {
+		return nil, fmt.Errorf("centraldogma: malformed JSON patch: %v", err)
+	}
+
+	doc, err := normalizeJSONValue(base)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		segments, err := pointerSegments(op.Path)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			doc, err = patchSet(doc, segments, op.Value, false)
+		case "replace":
+			doc, err = patchSet(doc, segments, op.Value, true)
+		case "remove":
+			doc, err = patchRemove(doc, segments)
+		case "test":
+			var current interface{}
+			current, err = patchGet(doc, segments)
+			if err == nil && !reflect.DeepEqual(current, op.Value) {
+				err = fmt.Errorf("centraldogma: test failed at %q", op.Path)
+			}
+		case "copy", "move":
+			var fromSegments []string
+			if fromSegments, err = pointerSegments(op.From); err != nil {
+				return nil, err
+			}
+			var value interface{}
+			if value, err = patchGet(doc, fromSegments); err != nil {
+				return nil, err
+			}
+			if op.Op == "move" {
+				if doc, err = patchRemove(doc, fromSegments); err != nil {
+					return nil, err
+				}
+			}
+			doc, err = patchSet(doc, segments, value, false)
+		default:
+			err = fmt.Errorf("centraldogma: unknown JSON patch op: %q", op.Op)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// pointerSegments splits an RFC 6901 JSON pointer into its unescaped
+// reference tokens.
+func pointerSegments(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("centraldogma: invalid JSON pointer: %q", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, segment := range segments {
+		segment = strings.ReplaceAll(segment, "~1", "/")
+		segments[i] = strings.ReplaceAll(segment, "~0", "~")
+	}
+	return segments, nil
+}
+
+func patchGet(node interface{}, segments []string) (interface{}, error) {
+	if len(segments) == 0 {
+		return node, nil
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[segments[0]]
+		if !ok {
+			return nil, fmt.Errorf("centraldogma: no such member: %q", segments[0])
+		}
+		return patchGet(child, segments[1:])
+	case []interface{}:
+		index, err := arrayIndex(segments[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		return patchGet(n[index], segments[1:])
+	default:
+		return nil, fmt.Errorf("centraldogma: cannot descend into %T", node)
+	}
+}
+
+func patchSet(node interface{}, segments []string, value interface{}, replace bool) (interface{}, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		key := segments[0]
+		if len(segments) == 1 {
+			if _, ok := n[key]; replace && !ok {
+				return nil, fmt.Errorf("centraldogma: no such member: %q", key)
+			}
+			n[key] = value
+			return n, nil
+		}
+		child, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("centraldogma: no such member: %q", key)
+		}
+		newChild, err := patchSet(child, segments[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+	case []interface{}:
+		if len(segments) == 1 {
+			if replace {
+				index, err := arrayIndex(segments[0], len(n), false)
+				if err != nil {
+					return nil, err
+				}
+				n[index] = value
+				return n, nil
+			}
+			index, err := arrayIndex(segments[0], len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+		index, err := arrayIndex(segments[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := patchSet(n[index], segments[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = newChild
+		return n, nil
+	default:
+		return nil, fmt.Errorf("centraldogma: cannot descend into %T", node)
+	}
+}
+
+func patchRemove(node interface{}, segments []string) (interface{}, error) {
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("centraldogma: cannot remove the document root")
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		key := segments[0]
+		child, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("centraldogma: no such member: %q", key)
+		}
+		if len(segments) == 1 {
+			delete(n, key)
+			return n, nil
+		}
+		newChild, err := patchRemove(child, segments[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(segments[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(segments) == 1 {
+			return append(n[:index], n[index+1:]...), nil
+		}
+		newChild, err := patchRemove(n[index], segments[1:])
+		if err != nil {
+			return nil, err
+		}
+		n[index] = newChild
+		return n, nil
+	default:
+		return nil, fmt.Errorf("centraldogma: cannot descend into %T", node)
+	}
+}
+
+// arrayIndex parses an array reference token. The "-" token refers to the
+// end of the array and is only valid when inserting.
+func arrayIndex(token string, length int, inserting bool) (int, error) {
+	if token == "-" && inserting {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (!inserting && index == length) {
+		return 0, fmt.Errorf("centraldogma: invalid array index: %q", token)
+	}
+	return index, nil
+}
+
+// applyTextPatch applies a unified diff to the specified text.
+func applyTextPatch(text, patch string) (string, error) {
+	baseLines := strings.Split(text, "\n")
+	var result []string
+	baseIndex := 0
+
+	lines := strings.Split(patch, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			// File headers carry no content.
+		case strings.HasPrefix(line, "@@"):
+			start, err := hunkStart(line)
+			if err != nil {
+				return "", err
+			}
+			if start-1 < baseIndex || start-1 > len(baseLines) {
+				return "", fmt.Errorf("centraldogma: hunk does not apply: %q", line)
+			}
+			result = append(result, baseLines[baseIndex:start-1]...)
+			baseIndex = start - 1
+		case strings.HasPrefix(line, " "):
+			if baseIndex >= len(baseLines) || baseLines[baseIndex] != line[1:] {
+				return "", fmt.Errorf("centraldogma: context mismatch at line %v", baseIndex+1)
+			}
+			result = append(result, line[1:])
+			baseIndex++
+		case strings.HasPrefix(line, "-"):
+			if baseIndex >= len(baseLines) || baseLines[baseIndex] != line[1:] {
+				return "", fmt.Errorf("centraldogma: removed line mismatch at line %v", baseIndex+1)
+			}
+			baseIndex++
+		case strings.HasPrefix(line, "+"):
+			result = append(result, line[1:])
+		case line == "":
+			// Trailing empty line of the patch.
+		default:
+			return "", fmt.Errorf("centraldogma: malformed patch line: %q", line)
+		}
+	}
+	result = append(result, baseLines[baseIndex:]...)
+	return strings.Join(result, "\n"), nil
+}
+
+// hunkStart parses the starting line of the original file from a hunk
+// header such as "@@ -2,3 +2,4 @@".
+func hunkStart(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("centraldogma: malformed hunk header: %q", header)
+	}
+	span := fields[1][1:]
+	if comma := strings.IndexByte(span, ','); comma != -1 {
+		span = span[:comma]
+	}
+	start, err := strconv.Atoi(span)
+	if err != nil {
+		return 0, fmt.Errorf("centraldogma: malformed hunk header: %q", header)
+	}
+	return start, nil
+}
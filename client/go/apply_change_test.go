@@ -0,0 +1,88 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyChangeJSONPatch(t *testing.T) {
+	base := map[string]interface{}{
+		"a": float64(1),
+		"b": map[string]interface{}{"c": "old"},
+	}
+	change := &Change{
+		Path: "/a.json",
+		Type: ApplyJSONPatch,
+		Content: json.RawMessage(`[` +
+			`{"op":"replace","path":"/b/c","value":"new"},` +
+			`{"op":"add","path":"/d","value":[1,2]},` +
+			`{"op":"remove","path":"/a"}]`),
+	}
+
+	applied, err := ApplyChange(base, change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"b": map[string]interface{}{"c": "new"},
+		"d": []interface{}{float64(1), float64(2)},
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied: %v, want %v", applied, want)
+	}
+}
+
+func TestApplyChangeTextPatch(t *testing.T) {
+	base := "foo\nbar\nbaz\n"
+	change := &Change{
+		Path: "/a.txt",
+		Type: ApplyTextPatch,
+		Content: "--- /a.txt\n+++ /a.txt\n" +
+			"@@ -1,3 +1,3 @@\n foo\n-bar\n+qux\n baz\n",
+	}
+
+	applied, err := ApplyChange(base, change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "foo\nqux\nbaz\n"; applied != want {
+		t.Errorf("applied: %q, want %q", applied, want)
+	}
+}
+
+func TestApplyChangeUpsertAndRemove(t *testing.T) {
+	applied, err := ApplyChange(nil, &Change{
+		Path: "/a.json", Type: UpsertJSON, Content: json.RawMessage(`{"a":1}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"a": float64(1)}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied: %v, want %v", applied, want)
+	}
+
+	applied, err = ApplyChange(map[string]interface{}{"a": float64(1)}, &Change{
+		Path: "/a.json", Type: Remove,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied != nil {
+		t.Errorf("applied: %v, want nil", applied)
+	}
+}
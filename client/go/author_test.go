@@ -0,0 +1,56 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import "testing"
+
+func TestParseAuthor(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Author
+		wantErr bool
+	}{
+		{input: "Alice <a@x.com>", want: Author{Name: "Alice", Email: "a@x.com"}},
+		{input: "a@x.com", want: Author{Name: "a", Email: "a@x.com"}},
+		{input: "  Bob Smith  <b@x.com>  ", want: Author{Name: "Bob Smith", Email: "b@x.com"}},
+		{input: "Alice <a@x.com", wantErr: true},
+		{input: "<a@x.com>", wantErr: true},
+		{input: "not-an-email", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, test := range tests {
+		author, err := ParseAuthor(test.input)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseAuthor(%q) did not fail", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAuthor(%q): %v", test.input, err)
+			continue
+		}
+		if *author != test.want {
+			t.Errorf("ParseAuthor(%q) = %+v, want %+v", test.input, *author, test.want)
+		}
+	}
+}
+
+func TestAuthorString(t *testing.T) {
+	author := &Author{Name: "Alice", Email: "a@x.com"}
+	if got, want := author.String(), "Alice <a@x.com>"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package centraldogma provides a Go client for accessing Central Dogma.
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScheme     = "http"
+	defaultBaseURL    = defaultScheme + "://localhost:36462/"
+	defaultPathPrefix = "api/v1/"
+
+	// anonymousToken is the token the server accepts for unauthenticated
+	// access when security is disabled.
+	anonymousToken = "anonymous"
+
+	// maxErrorBodyBytes bounds how much of an error response body is kept in
+	// an APIError.
+	maxErrorBodyBytes = 4096
+)
+
+// Client is a Central Dogma client which communicates with a Central Dogma
+// server over HTTP. A Client is safe for concurrent use by multiple
+// goroutines.
+type Client struct {
+	client     *http.Client
+	baseURL    *url.URL
+	token      string
+	strictAuth bool
+	metrics    MetricsFunc
+	retry      *retryConfig
+	// ephemeralSession makes login ask for a session that is not remembered
+	// across restarts of the server-side session store.
+	ephemeralSession bool
+
+	project *projectService
+	repo    *repositoryService
+	content *contentService
+	watch   *watchService
+}
+
+// service is embedded in each API service so that they can share a single
+// Client.
+type service struct {
+	client *Client
+}
+
+// ClientOption configures the Client returned by NewClient and
+// NewClientWithToken.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	httpClient       *http.Client
+	strictAuth       bool
+	secureScheme     bool
+	metrics          MetricsFunc
+	retry            *retryConfig
+	ephemeralSession bool
+	endpointGroup    *EndpointGroup
+	proxyURL         string
+	noRedirects      bool
+
+	maxIdleConns      int
+	idleConnTimeout   time.Duration
+	disableKeepAlives bool
+}
+
+// MetricsFunc observes one API request. op is the HTTP method and the
+// request path, statusCode is zero when no response was received.
+type MetricsFunc func(op string, duration time.Duration, statusCode int, err error)
+
+// WithHTTPClient makes the client send its requests with the specified
+// *http.Client instead of the default one.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(config *clientConfig) {
+		config.httpClient = httpClient
+	}
+}
+
+// WithMetrics invokes the specified callback for every API request the
+// client sends, so that request counts, latencies and error rates can be fed
+// into Prometheus or any other collector without this package depending on a
+// metrics library.
+func WithMetrics(metrics MetricsFunc) ClientOption {
+	return func(config *clientConfig) {
+		config.metrics = metrics
+	}
+}
+
+// WithSecureDefaultScheme makes a base URL without a scheme default to
+// https when a token is configured, so that credentials are not silently
+// sent over plaintext just because the scheme was omitted. Without this
+// option a bare host defaults to http.
+func WithSecureDefaultScheme() ClientOption {
+	return func(config *clientConfig) {
+		config.secureScheme = true
+	}
+}
+
+// WithEndpointGroup makes the client route every request to a healthy
+// endpoint of the specified group instead of the single base URL, so that a
+// failing replica is avoided automatically. The base URL given to the
+// constructor only contributes the path prefix.
+func WithEndpointGroup(group *EndpointGroup) ClientOption {
+	return func(config *clientConfig) {
+		config.endpointGroup = group
+	}
+}
+
+// WithProxy routes every request of the client through the proxy at the
+// specified URL, overriding the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables which the default transport already honors. Use it
+// when the proxy differs from the process-wide environment.
+func WithProxy(proxyURL string) ClientOption {
+	return func(config *clientConfig) {
+		config.proxyURL = proxyURL
+	}
+}
+
+// WithNoRedirects makes the client fail a request that is answered with a
+// redirect instead of silently following it. A misconfigured proxy that
+// redirects to a login page otherwise surfaces as a confusing JSON decode
+// failure on the returned HTML; with this option the error names the
+// redirect target.
+func WithNoRedirects() ClientOption {
+	return func(config *clientConfig) {
+		config.noRedirects = true
+	}
+}
+
+// WithMaxIdleConns sets how many idle connections the transport of the
+// client keeps for reuse, both in total and to the single Central Dogma
+// host. The transport default keeps 100 in total but only 2 per host, which
+// throttles a high-throughput batch importer; such a caller should raise
+// this to its level of concurrency.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(config *clientConfig) {
+		config.maxIdleConns = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept before it is
+// closed. The transport default is 90 seconds; a short-lived CLI run can
+// lower it for aggressive cleanup, a busy service can raise it to avoid
+// reconnecting between bursts.
+func WithIdleConnTimeout(timeout time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.idleConnTimeout = timeout
+	}
+}
+
+// WithDisableKeepAlives makes the client open a fresh connection for every
+// request instead of reusing idle ones. This trades latency for never
+// holding a connection open, which suits one-shot invocations behind
+// aggressive middleboxes.
+func WithDisableKeepAlives() ClientOption {
+	return func(config *clientConfig) {
+		config.disableKeepAlives = true
+	}
+}
+
+// WithEphemeralSession makes the login performed by NewClient request a
+// session that is not remembered by the server (remember_me=false), so that
+// a short-lived invocation such as a CLI run does not leave a durable
+// session behind. Without this option a durable session is requested, which
+// suits long-running services.
+func WithEphemeralSession() ClientOption {
+	return func(config *clientConfig) {
+		config.ephemeralSession = true
+	}
+}
+
+// WithStrictAuth makes the client fail a request when it has no credentials
+// instead of falling back to the anonymous token, so that a forgotten token
+// surfaces as a clear client-side error rather than a confusing 403.
+func WithStrictAuth() ClientOption {
+	return func(config *clientConfig) {
+		config.strictAuth = true
+	}
+}
+
+// NewClientWithToken returns a Central Dogma client which authorizes its
+// requests with the specified token.
+func NewClientWithToken(baseURL, token string, opts ...ClientOption) (*Client, error) {
+	config := &clientConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	scheme := defaultScheme
+	if config.secureScheme && token != "" {
+		scheme = "https"
+	}
+	normalized, err := normalizeURL(baseURL, scheme)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := config.httpClient
+	if httpClient == nil {
+		// The zero client uses http.DefaultTransport, which honors the
+		// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+		httpClient = &http.Client{}
+	}
+	if config.maxIdleConns > 0 || config.idleConnTimeout > 0 || config.disableKeepAlives {
+		transport, err := cloneTransport(httpClient, "the transport tuning options")
+		if err != nil {
+			return nil, err
+		}
+		if config.maxIdleConns > 0 {
+			transport.MaxIdleConns = config.maxIdleConns
+			transport.MaxIdleConnsPerHost = config.maxIdleConns
+		}
+		if config.idleConnTimeout > 0 {
+			transport.IdleConnTimeout = config.idleConnTimeout
+		}
+		transport.DisableKeepAlives = config.disableKeepAlives
+		tuned := *httpClient
+		tuned.Transport = transport
+		httpClient = &tuned
+	}
+	if config.proxyURL != "" {
+		proxy, err := url.Parse(config.proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("centraldogma: invalid proxy URL: %v", err)
+		}
+		proxiedTransport, err := cloneTransport(httpClient, "WithProxy")
+		if err != nil {
+			return nil, err
+		}
+		proxiedTransport.Proxy = http.ProxyURL(proxy)
+		proxied := *httpClient
+		proxied.Transport = proxiedTransport
+		httpClient = &proxied
+	}
+	if config.noRedirects {
+		unredirected := *httpClient
+		unredirected.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return fmt.Errorf("centraldogma: unexpected redirect to %v", req.URL)
+		}
+		httpClient = &unredirected
+	}
+	if config.endpointGroup != nil {
+		routed := *httpClient
+		routed.Transport = &endpointTransport{group: config.endpointGroup, base: httpClient.Transport}
+		httpClient = &routed
+	}
+
+	c := &Client{
+		client:           httpClient,
+		baseURL:          normalized,
+		token:            token,
+		strictAuth:       config.strictAuth,
+		metrics:          config.metrics,
+		retry:            config.retry,
+		ephemeralSession: config.ephemeralSession,
+	}
+	c.project = &projectService{client: c}
+	c.repo = &repositoryService{client: c}
+	c.content = &contentService{client: c}
+	c.watch = &watchService{client: c}
+	return c, nil
+}
+
+// cloneTransport returns a clone of the *http.Transport the specified client
+// sends its requests with, so that an option can adjust the transport
+// without mutating one shared with other clients. option names the caller
+// for the error reported on a custom non-Transport RoundTripper.
+func cloneTransport(httpClient *http.Client, option string) (*http.Transport, error) {
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		if httpClient.Transport != nil {
+			return nil, fmt.Errorf(
+				"centraldogma: %v requires an *http.Transport, have %T", option, httpClient.Transport)
+		}
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	return transport.Clone(), nil
+}
+
+// NewAnonymousClient returns a Central Dogma client which sends its
+// requests without credentials, authorizing as the anonymous user. It makes
+// the intent explicit instead of passing the magic "anonymous" token
+// around; the server must have security disabled or allow anonymous access.
+func NewAnonymousClient(baseURL string, opts ...ClientOption) (*Client, error) {
+	return NewClientWithToken(baseURL, anonymousToken, opts...)
+}
+
+// NewClientNoLogin returns a Central Dogma client without performing any
+// network I/O: unlike NewClient, no login or probe request is sent during
+// construction. The credentials are validated by the server on the first
+// call the client makes.
+func NewClientNoLogin(baseURL, token string, opts ...ClientOption) (*Client, error) {
+	return NewClientWithToken(baseURL, token, opts...)
+}
+
+// NewClient returns a Central Dogma client which logs into the server with
+// the specified username and password. Note that login is performed while
+// constructing the client, so this function can block until the server
+// responds. Use NewClientContext to bound the login with a context.
+func NewClient(baseURL, username, password string, opts ...ClientOption) (*Client, error) {
+	return NewClientContext(context.Background(), baseURL, username, password, opts...)
+}
+
+// NewClientContext returns a Central Dogma client which logs into the server
+// with the specified username and password. The security probe and the login
+// request honor the specified context, so construction fails instead of
+// hanging when the context expires before the server responds.
+func NewClientContext(ctx context.Context, baseURL, username, password string,
+	opts ...ClientOption) (*Client, error) {
+	c, err := NewClientWithToken(baseURL, "", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := c.SecurityEnabledContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		c.token = anonymousToken
+		return c, nil
+	}
+
+	token, err := c.login(ctx, username, password)
+	if err != nil {
+		return nil, err
+	}
+	c.token = token
+	return c, nil
+}
+
+// normalizeURL parses the specified baseURL, prepending the given scheme
+// and appending a trailing slash if absent.
+func normalizeURL(baseURL, scheme string) (*url.URL, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = scheme + "://" + baseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return url.Parse(baseURL)
+}
+
+// newRequest creates an API request whose URL is resolved against the base
+// URL of the client. The Authorization header falls back to the anonymous
+// token when the client has no credentials, unless WithStrictAuth was set,
+// in which case the missing credentials are an error.
+func (c *Client) newRequest(method, urlStr string, body io.Reader) (*http.Request, error) {
+	u, err := c.baseURL.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Authorization") == "" {
+		token := c.token
+		if token == "" {
+			if c.strictAuth {
+				return nil, errors.New(
+					"centraldogma: no credentials configured; set a token or drop WithStrictAuth")
+			}
+			token = anonymousToken
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// do sends an API request and decodes the JSON response body into resContent
+// unless it is nil. It returns the HTTP status code of the response even when
+// it returns a non-nil error.
+func (c *Client) do(ctx context.Context, req *http.Request, resContent interface{}) (int, error) {
+	start := time.Now()
+	code, err := c.doWithRetries(ctx, req, resContent)
+	if c.metrics != nil {
+		c.metrics(req.Method+" "+req.URL.Path, time.Since(start), code, err)
+	}
+	return code, err
+}
+
+func (c *Client) doRequest(ctx context.Context, req *http.Request, resContent interface{}) (int, error) {
+	req = req.WithContext(ctx)
+	logger.Printf("%v %v", req.Method, req.URL)
+	res, err := c.client.Do(req)
+	if err != nil {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+		return 0, classifyConnectionError(err)
+	}
+	defer res.Body.Close()
+
+	if code := res.StatusCode; code < 200 || code >= 300 {
+		apiError := &APIError{Method: req.Method, URL: req.URL.String(), StatusCode: code}
+		// Retain a bounded snapshot of the body: it is the server's only
+		// explanation of the failure and is closed before the caller sees it.
+		snapshot, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodyBytes))
+		apiError.Body = strings.TrimSpace(string(snapshot))
+		if strings.HasPrefix(res.Header.Get("Content-Type"), "application/json") {
+			detail := new(struct {
+				Exception string `json:"exception"`
+				Message   string `json:"message"`
+			})
+			if err := json.Unmarshal(snapshot, detail); err == nil {
+				apiError.Exception = detail.Exception
+				apiError.Message = detail.Message
+			}
+		}
+		io.Copy(io.Discard, res.Body)
+		return code, apiError
+	}
+
+	if resContent != nil {
+		if err := json.NewDecoder(res.Body).Decode(resContent); err != nil && err != io.EOF {
+			return res.StatusCode, err
+		}
+	}
+	return res.StatusCode, nil
+}
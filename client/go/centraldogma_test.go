@@ -0,0 +1,337 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStrictAuthRejectsMissingCredentials(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "", WithStrictAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.ListProjects(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("err: %v, want a missing-credentials error", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests: %v, want the request to fail before being sent", requests)
+	}
+}
+
+func TestAnonymousFallbackWithoutStrictAuth(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer anonymous" {
+		t.Errorf("Authorization: %q, want the anonymous default", authorization)
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	type observation struct {
+		op         string
+		statusCode int
+		err        error
+	}
+	var observations []observation
+	client, err := NewClientWithToken(server.URL, "",
+		WithMetrics(func(op string, duration time.Duration, statusCode int, err error) {
+			observations = append(observations, observation{op, statusCode, err})
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(observations) != 1 {
+		t.Fatalf("observations: %v, want 1", len(observations))
+	}
+	if observations[0].op != "GET /api/v1/projects" {
+		t.Errorf("op: %q", observations[0].op)
+	}
+	if observations[0].statusCode != http.StatusOK || observations[0].err != nil {
+		t.Errorf("observation: %+v", observations[0])
+	}
+}
+
+func TestSecureDefaultScheme(t *testing.T) {
+	client, err := NewClientWithToken("host:36462", "token", WithSecureDefaultScheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.baseURL.String(); got != "https://host:36462/" {
+		t.Errorf("base URL: %v, want https", got)
+	}
+
+	// Without a token there is nothing to protect; the http default stays.
+	client, err = NewClientWithToken("host:36462", "", WithSecureDefaultScheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.baseURL.String(); got != "http://host:36462/" {
+		t.Errorf("base URL: %v, want http", got)
+	}
+
+	// An explicit scheme always wins.
+	client, err = NewClientWithToken("http://host:36462", "token", WithSecureDefaultScheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := client.baseURL.String(); got != "http://host:36462/" {
+		t.Errorf("base URL: %v, want the explicit http", got)
+	}
+}
+
+func TestNewClientContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An unresponsive auth server: hold the request until the client
+		// gives up.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewClientContext(ctx, server.URL, "user", "pass")
+	if err == nil {
+		t.Fatal("NewClientContext succeeded, want a deadline error")
+	}
+	if !strings.Contains(err.Error(), "deadline") {
+		t.Errorf("err: %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("construction took %v, want it bounded by the context", elapsed)
+	}
+}
+
+func TestNewClientNoLoginMakesNoRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientNoLogin(server.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Fatalf("requests during construction: %v, want 0", requests)
+	}
+
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("requests: %v, want the first call to hit the server", requests)
+	}
+}
+
+func TestNewAnonymousClient(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client, err := NewAnonymousClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer anonymous" {
+		t.Errorf("Authorization: %q, want the anonymous token", authorization)
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"foo"}]`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":1,"path":"/a.json","type":"JSON","content":{"a":1}}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":2}`)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One client, many goroutines. Run with -race to prove the shared
+	// services and transport are safe to reuse.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				if _, err := client.ListProjects(context.Background()); err != nil {
+					t.Error(err)
+				}
+				query := &Query{Path: "/a.json", Type: Identity}
+				if _, err := client.GetFile(context.Background(), "foo", "bar", "-1", query); err != nil {
+					t.Error(err)
+				}
+				change := &Change{Path: "/a.json", Type: UpsertJSON, Content: json.RawMessage(`{"a":2}`)}
+				if _, err := client.Push(context.Background(), "foo", "bar", "-1",
+					&CommitMessage{Summary: "update"}, []*Change{change}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxiedHost string
+	// An HTTP proxy receives the absolute request URI and answers in place
+	// of the unreachable origin.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		fmt.Fprint(w, `[]`)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClientWithToken("http://origin.invalid", "", WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ListProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if proxiedHost != "origin.invalid" {
+		t.Errorf("proxied host: %q, want the origin routed through the proxy", proxiedHost)
+	}
+}
+
+func TestWithProxyInvalidURL(t *testing.T) {
+	if _, err := NewClientWithToken("http://example.com", "", WithProxy("://bad")); err == nil {
+		t.Error("NewClientWithToken accepted an invalid proxy URL")
+	}
+}
+
+func TestWithNoRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://login.example.com/sso", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "", WithNoRedirects())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.ListProjects(context.Background())
+	if err == nil {
+		t.Fatal("the redirect was followed")
+	}
+	if !strings.Contains(err.Error(), "unexpected redirect to http://login.example.com/sso") {
+		t.Errorf("err: %v, want it to name the redirect target", err)
+	}
+}
+
+func TestTransportTuningOptions(t *testing.T) {
+	client, err := NewClientWithToken("localhost:36462", "",
+		WithMaxIdleConns(64), WithIdleConnTimeout(10*time.Second), WithDisableKeepAlives())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport, ok := client.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport: %T, want *http.Transport", client.client.Transport)
+	}
+	if transport.MaxIdleConns != 64 || transport.MaxIdleConnsPerHost != 64 {
+		t.Errorf("max idle conns: %v/%v, want 64/64",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 10*time.Second {
+		t.Errorf("idle conn timeout: %v, want 10s", transport.IdleConnTimeout)
+	}
+	if !transport.DisableKeepAlives {
+		t.Error("keep-alives enabled, want disabled")
+	}
+	// The shared default transport must not have been mutated.
+	if http.DefaultTransport.(*http.Transport).DisableKeepAlives {
+		t.Error("http.DefaultTransport was mutated")
+	}
+}
+
+func TestTransportTuningRejectsCustomRoundTripper(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, nil
+	})}
+	_, err := NewClientWithToken("localhost:36462", "",
+		WithHTTPClient(httpClient), WithMaxIdleConns(64))
+	if err == nil {
+		t.Fatal("NewClientWithToken accepted transport tuning on a custom RoundTripper, want an error")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
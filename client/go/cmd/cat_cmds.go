@@ -0,0 +1,69 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func catCommand(client *centraldogma.Client, out, errOut io.Writer, args []string) error {
+	flags := flag.NewFlagSet("cat", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	revision := flags.String("revision", defaultRevision, "the revision of the file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("a file path is required")
+	}
+
+	project, repo, path, err := SplitPath(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := pathutil.EnsureFilePath(path); err != nil {
+		return err
+	}
+
+	query := &centraldogma.Query{Path: path, Type: centraldogma.Identity}
+	entry, err := client.GetFile(context.Background(), project, repo, *revision, query)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Type {
+	case centraldogma.JSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, entry.Content, "", "  "); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, buf.String())
+	case centraldogma.Text:
+		fmt.Fprintln(out, string(entry.Content))
+	case centraldogma.Directory:
+		return fmt.Errorf("cannot cat a directory: %v (use ls instead)", entry.Path)
+	default:
+		return fmt.Errorf("unknown entry type: %v", entry.Type)
+	}
+	return nil
+}
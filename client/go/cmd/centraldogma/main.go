@@ -0,0 +1,27 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Command centraldogma is the legacy command line interface for Central
+// Dogma. Prefer the dogma command for new scripts.
+package main
+
+import (
+	"os"
+
+	"go.linecorp.com/centraldogma/cmd"
+)
+
+func main() {
+	os.Exit(cmd.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd implements the legacy Central Dogma command line interface.
+// It is kept for backward compatibility; new features are added to the
+// dogma command instead.
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+// defaultRevision is the revision used when the user did not specify one.
+// It is shared with the dogma CLI so that both binaries send identical
+// requests for the same input.
+const defaultRevision = pathutil.DefaultRevision
+
+// Run executes the legacy command line with the specified arguments and
+// returns the exit code of the process.
+func Run(args []string, out, errOut io.Writer) int {
+	flags := flag.NewFlagSet("centraldogma", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	connect := flags.String("connect", "localhost:36462",
+		"the host or URL of the Central Dogma server")
+	token := flags.String("token", os.Getenv("DOGMA_TOKEN"),
+		"the token to authorize the requests with")
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	rest := flags.Args()
+	if len(rest) == 0 {
+		fmt.Fprintln(errOut, "usage: centraldogma [global options] <ls|cat> [options] [arguments]")
+		return 2
+	}
+
+	client, err := centraldogma.NewClientWithToken(*connect, *token)
+	if err != nil {
+		fmt.Fprintf(errOut, "centraldogma: %v\n", err)
+		return 1
+	}
+
+	switch rest[0] {
+	case "ls":
+		err = lsCommand(client, out, errOut, rest[1:])
+	case "cat":
+		err = catCommand(client, out, errOut, rest[1:])
+	default:
+		fmt.Fprintf(errOut, "centraldogma: unknown command: %v\n", rest[0])
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "centraldogma: %v\n", err)
+		return 1
+	}
+	return 0
+}
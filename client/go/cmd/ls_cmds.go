@@ -0,0 +1,51 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func lsCommand(client *centraldogma.Client, out, errOut io.Writer, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	revision := flags.String("revision", defaultRevision, "the revision to list")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("a repository path is required")
+	}
+
+	project, repo, path, err := SplitPath(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	entries, err := client.ListFiles(context.Background(), project, repo, *revision,
+		pathutil.ListPattern(path))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		fmt.Fprintln(out, entry.Path)
+	}
+	return nil
+}
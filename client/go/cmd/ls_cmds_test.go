@@ -0,0 +1,95 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func TestLsCommand(t *testing.T) {
+	var gotPath, gotRevision string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotRevision = r.URL.Query().Get("revision")
+		fmt.Fprint(w, `[{"path":"/a/b.json","type":"JSON"}]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "ls", "foo/bar/a/"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	if want := "/api/v1/projects/foo/repos/bar/list/a/*"; gotPath != want {
+		t.Errorf("request path: %v, want %v", gotPath, want)
+	}
+	// Both CLIs now share pathutil.DefaultRevision instead of the old
+	// "head" default.
+	if gotRevision != "-1" {
+		t.Errorf("revision: %v, want -1", gotRevision)
+	}
+	if want := "/a/b.json\n"; out.String() != want {
+		t.Errorf("output: %q, want %q", out.String(), want)
+	}
+}
+
+func TestSplitPathMatchesPathutil(t *testing.T) {
+	for _, arg := range []string{"foo/bar", "foo/bar/a/b.json", "foo/bar/a/"} {
+		project, repo, path, err := SplitPath(arg)
+		wantProject, wantRepo, wantPath, wantErr := pathutil.SplitPath(arg)
+		if err != nil || wantErr != nil {
+			t.Fatalf("SplitPath(%q): %v, %v", arg, err, wantErr)
+		}
+		if project != wantProject || repo != wantRepo || path != wantPath {
+			t.Errorf("SplitPath(%q) = %v, %v, %v, want %v, %v, %v",
+				arg, project, repo, path, wantProject, wantRepo, wantPath)
+		}
+	}
+}
+
+func TestLsCommandTrailingSlashSemantics(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		// A trailing slash lists the directory contents; without one the
+		// named entry itself is matched.
+		{arg: "foo/bar/a/", want: "/api/v1/projects/foo/repos/bar/list/a/*"},
+		{arg: "foo/bar/a", want: "/api/v1/projects/foo/repos/bar/list/a"},
+	}
+	for _, test := range tests {
+		var gotPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			fmt.Fprint(w, `[]`)
+		}))
+
+		out := new(bytes.Buffer)
+		if code := Run([]string{"-connect", server.URL, "ls", test.arg}, out, out); code != 0 {
+			t.Fatalf("ls %v: exit code %v", test.arg, code)
+		}
+		if gotPath != test.want {
+			t.Errorf("ls %v: request path %v, want %v", test.arg, gotPath, test.want)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import "go.linecorp.com/centraldogma/internal/pathutil"
+
+// SplitPath splits an argument of the form "project/repository[/path]" into
+// the project name, the repository name and the path in the repository. It
+// shares its behavior with the dogma CLI via the pathutil package.
+func SplitPath(arg string) (project, repo, path string, err error) {
+	return pathutil.SplitPath(arg)
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// CommitStream returns a channel that emits every commit pushed to the
+// files matched by the path pattern, in revision order, starting after the
+// current head. It is built on a repository watcher: when the watcher
+// notices a new revision, the commits between the previously seen revision
+// and the new one are fetched and emitted. The channel is closed when the
+// context is canceled.
+func (c *Client) CommitStream(ctx context.Context, projectName, repoName, pathPattern string) (<-chan *Commit, error) {
+	watcher, err := c.RepoWatcher(projectName, repoName, pathPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// The listener must not block, so revisions are handed to the emitting
+	// goroutine through a buffered channel, keeping only the newest when
+	// the consumer lags: the range fetch catches up on anything in between.
+	revisions := make(chan int, 1)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		select {
+		case revisions <- revision:
+		default:
+			select {
+			case <-revisions:
+			default:
+			}
+			revisions <- revision
+		}
+	}); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	commits := make(chan *Commit)
+	go func() {
+		defer close(commits)
+		defer watcher.Close()
+
+		last := 0
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case revision := <-revisions:
+				if last == 0 {
+					// The initial notification only establishes the baseline.
+					last = revision
+					continue
+				}
+				if revision <= last {
+					continue
+				}
+				fetched, err := c.GetHistory(ctx, projectName, repoName,
+					strconv.Itoa(last+1), strconv.Itoa(revision), pathPattern, 0)
+				if err != nil {
+					// The watcher will notice the revision again; the next
+					// range fetch covers this one too.
+					continue
+				}
+				sort.Slice(fetched, func(i, j int) bool {
+					return fetched[i].Revision < fetched[j].Revision
+				})
+				for _, commit := range fetched {
+					select {
+					case commits <- commit:
+					case <-ctx.Done():
+						return
+					}
+				}
+				last = revision
+			}
+		}
+	}()
+	return commits, nil
+}
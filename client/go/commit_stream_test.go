@@ -0,0 +1,100 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCommitStream(t *testing.T) {
+	var mu sync.Mutex
+	head := 1
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":1}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/",
+		func(w http.ResponseWriter, r *http.Request) {
+			known := r.Header.Get("If-None-Match")
+			mu.Lock()
+			current := head
+			mu.Unlock()
+			if known == fmt.Sprint(current) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprintf(w, `{"revision":%v}`, current)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/commits/",
+		func(w http.ResponseWriter, r *http.Request) {
+			from := strings.TrimPrefix(r.URL.Path, "/api/v1/projects/foo/repos/bar/commits/")
+			to := r.URL.Query().Get("to")
+			if from != to {
+				t.Errorf("range: %v..%v, want a single-revision fetch", from, to)
+			}
+			fmt.Fprintf(w, `[{"revision":%v,"commitMessage":{"summary":"commit %v"}}]`, from, from)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	commits, err := client.CommitStream(ctx, "foo", "bar", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for want := 2; want <= 3; want++ {
+		mu.Lock()
+		head = want
+		mu.Unlock()
+
+		select {
+		case commit := <-commits:
+			if commit.Revision != want {
+				t.Fatalf("revision: %v, want %v", commit.Revision, want)
+			}
+			if commit.CommitMessage.Summary != fmt.Sprintf("commit %v", want) {
+				t.Errorf("summary: %q", commit.CommitMessage.Summary)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no commit for revision %v", want)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-commits:
+		if ok {
+			t.Error("received a commit after cancellation")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the stream was not closed on cancellation")
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ConnectionErrorCategory classifies a transport failure so that callers can
+// decide whether to retry, fail over to another endpoint, or alert.
+type ConnectionErrorCategory int
+
+// The allowed values of ConnectionErrorCategory.
+const (
+	// ConnectionDNS means the host name could not be resolved.
+	ConnectionDNS ConnectionErrorCategory = iota + 1
+	// ConnectionDial means the TCP connection could not be established.
+	ConnectionDial
+	// ConnectionTLS means the TLS handshake or certificate validation
+	// failed.
+	ConnectionTLS
+	// ConnectionTimeout means the request did not complete in time.
+	ConnectionTimeout
+)
+
+var connectionErrorCategoryNames = map[ConnectionErrorCategory]string{
+	ConnectionDNS:     "DNS",
+	ConnectionDial:    "DIAL",
+	ConnectionTLS:     "TLS",
+	ConnectionTimeout: "TIMEOUT",
+}
+
+// String returns the name of the category.
+func (c ConnectionErrorCategory) String() string {
+	if name, ok := connectionErrorCategoryNames[c]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// ConnectionError is a transport failure classified into a category. It
+// wraps the original error, which remains reachable with errors.As and
+// errors.Unwrap.
+type ConnectionError struct {
+	Category ConnectionErrorCategory
+	Err      error
+}
+
+// Error implements the error interface.
+func (e *ConnectionError) Error() string {
+	return fmt.Sprintf("centraldogma: connection error (%v): %v", e.Category, e.Err)
+}
+
+// Unwrap returns the original transport error.
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// classifyConnectionError wraps common transport errors into a
+// ConnectionError. Errors that do not match any category are returned
+// unchanged.
+func classifyConnectionError(err error) error {
+	var dnsError *net.DNSError
+	if errors.As(err, &dnsError) {
+		return &ConnectionError{Category: ConnectionDNS, Err: err}
+	}
+
+	var (
+		recordHeaderError  tls.RecordHeaderError
+		unknownAuthority   x509.UnknownAuthorityError
+		certificateInvalid x509.CertificateInvalidError
+		hostnameError      x509.HostnameError
+	)
+	if errors.As(err, &recordHeaderError) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &certificateInvalid) || errors.As(err, &hostnameError) {
+		return &ConnectionError{Category: ConnectionTLS, Err: err}
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return &ConnectionError{Category: ConnectionTimeout, Err: err}
+	}
+
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return &ConnectionError{Category: ConnectionDial, Err: err}
+	}
+	return err
+}
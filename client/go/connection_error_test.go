@@ -0,0 +1,83 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// dialError is a stub dialer which fails every connection attempt with the
+// given error.
+func dialError(err error) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, err
+	}
+}
+
+func TestConnectionErrorClassification(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ConnectionErrorCategory
+	}{
+		{
+			name: "dns",
+			err:  &net.DNSError{Err: "no such host", Name: "dogma.invalid", IsNotFound: true},
+			want: ConnectionDNS,
+		},
+		{
+			name: "dial",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			want: ConnectionDial,
+		},
+		{
+			name: "tls",
+			err:  tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"},
+			want: ConnectionTLS,
+		},
+		{
+			name: "timeout",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			want: ConnectionTimeout,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpClient := &http.Client{Transport: &http.Transport{DialContext: dialError(test.err)}}
+			client, err := NewClientWithToken("dogma.local", "", WithHTTPClient(httpClient))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.ListProjects(context.Background())
+			var connectionError *ConnectionError
+			if !errors.As(err, &connectionError) {
+				t.Fatalf("err: %T (%v), want *ConnectionError", err, err)
+			}
+			if connectionError.Category != test.want {
+				t.Errorf("category: %v, want %v", connectionError.Category, test.want)
+			}
+			if !errors.Is(err, test.err) {
+				t.Errorf("original error not wrapped: %v", err)
+			}
+		})
+	}
+}
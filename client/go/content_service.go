@@ -0,0 +1,854 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type contentService service
+
+// EntryType represents the type of an Entry.
+type EntryType int
+
+// The allowed values of EntryType.
+const (
+	JSON EntryType = iota + 1
+	Text
+	Directory
+	Binary
+)
+
+var entryTypeNames = map[EntryType]string{
+	JSON:      "JSON",
+	Text:      "TEXT",
+	Directory: "DIRECTORY",
+	Binary:    "BINARY",
+}
+
+// String returns the server-side name of the entry type.
+func (e EntryType) String() string {
+	if name, ok := entryTypeNames[e]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// MarshalJSON encodes the entry type into its server-side name.
+func (e EntryType) MarshalJSON() ([]byte, error) {
+	name, ok := entryTypeNames[e]
+	if !ok {
+		return nil, fmt.Errorf("centraldogma: unknown entry type: %d", int(e))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes the server-side name of the entry type.
+func (e *EntryType) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+	for entryType, entryTypeName := range entryTypeNames {
+		if name == entryTypeName {
+			*e = entryType
+			return nil
+		}
+	}
+	return fmt.Errorf("centraldogma: unknown entry type: %q", name)
+}
+
+// EntryContent is the content of an Entry. JSON content is stored in its raw
+// encoded form while text content is stored as its bytes. A JSONPath query
+// matching multiple nodes yields a JSON array, which is preserved as-is.
+type EntryContent []byte
+
+// UnmarshalJSON keeps JSON content as-is and unquotes text content.
+func (e *EntryContent) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var text string
+		if err := json.Unmarshal(b, &text); err != nil {
+			return err
+		}
+		*e = []byte(text)
+		return nil
+	}
+	*e = append((*e)[:0], b...)
+	return nil
+}
+
+// MarshalJSON encodes JSON content as-is and quotes text content.
+func (e EntryContent) MarshalJSON() ([]byte, error) {
+	if json.Valid(e) {
+		return e, nil
+	}
+	return json.Marshal(string(e))
+}
+
+// Entry represents an entry in a repository.
+type Entry struct {
+	Path     string       `json:"path"`
+	Type     EntryType    `json:"type"`
+	Content  EntryContent `json:"content,omitempty"`
+	Revision int          `json:"revision,omitempty"`
+	URL      string       `json:"url,omitempty"`
+	// Size is the content size in bytes, reported only by metadata
+	// listings such as ListFilesWithMeta.
+	Size       int64  `json:"size,omitempty"`
+	ModifiedAt string `json:"modifiedAt,omitempty"`
+}
+
+// ContentBytes returns the canonical byte representation of the entry
+// content regardless of its type: JSON content is pretty-printed, text
+// content is returned as-is and binary content is base64-decoded. It lets a
+// caller write the entries of a mixed repository to disk uniformly without
+// switching on Type.
+func (e *Entry) ContentBytes() ([]byte, error) {
+	switch e.Type {
+	case JSON:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(e.Content), "", "  "); err != nil {
+			return nil, fmt.Errorf("centraldogma: %v is not valid JSON: %v", e.Path, err)
+		}
+		indented.WriteByte('\n')
+		return indented.Bytes(), nil
+	case Binary:
+		decoded, err := base64.StdEncoding.DecodeString(string(e.Content))
+		if err != nil {
+			return nil, fmt.Errorf("centraldogma: %v is not valid base64: %v", e.Path, err)
+		}
+		return decoded, nil
+	case Directory:
+		return nil, fmt.Errorf("centraldogma: %v is a directory and has no content", e.Path)
+	default:
+		return e.Content, nil
+	}
+}
+
+// MarshalJSON omits the content field of a directory entry, which never has
+// content, instead of emitting a misleading empty value.
+func (e Entry) MarshalJSON() ([]byte, error) {
+	if e.Type == Directory {
+		e.Content = nil
+	}
+	type rawEntry Entry // shed the methods to avoid recursion
+	return json.Marshal(rawEntry(e))
+}
+
+// QueryType represents the type of a Query.
+type QueryType int
+
+// The allowed values of QueryType.
+const (
+	// Identity retrieves the content as-is.
+	Identity QueryType = iota + 1
+	// JSONPath applies a series of JSON path expressions to the content.
+	JSONPath
+)
+
+// Query is a query on a file. When Type is JSONPath, Path must end with
+// ".json" and Expressions must contain one or more JSON path expressions.
+type Query struct {
+	Path string
+	Type QueryType
+	// Expressions is the JSON path expressions applied in order to the
+	// content when Type is JSONPath.
+	Expressions []string
+}
+
+// Commit represents a commit in a repository.
+type Commit struct {
+	Revision      int            `json:"revision"`
+	Author        *Author        `json:"author,omitempty"`
+	PushedAt      string         `json:"pushedAt,omitempty"`
+	CommitMessage *CommitMessage `json:"commitMessage,omitempty"`
+	Entries       []*Entry       `json:"entries,omitempty"`
+}
+
+// CommitMessage is a message describing a commit.
+type CommitMessage struct {
+	Summary string `json:"summary"`
+	Detail  string `json:"detail,omitempty"`
+	Markup  Markup `json:"markup,omitempty"`
+}
+
+// Markup is the markup language of the detail of a commit message.
+type Markup string
+
+// The markup values the server accepts. An empty Markup is allowed and lets
+// the server apply its default.
+const (
+	Plaintext Markup = "PLAINTEXT"
+	Markdown  Markup = "MARKDOWN"
+)
+
+// valid reports whether the markup is one the server accepts.
+func (m Markup) valid() bool {
+	return m == "" || m == Plaintext || m == Markdown
+}
+
+// ChangeType represents the type of a Change.
+type ChangeType int
+
+// The allowed values of ChangeType.
+const (
+	UpsertJSON ChangeType = iota + 1
+	UpsertText
+	Remove
+	Rename
+	ApplyJSONPatch
+	ApplyTextPatch
+)
+
+var changeTypeNames = map[ChangeType]string{
+	UpsertJSON:     "UPSERT_JSON",
+	UpsertText:     "UPSERT_TEXT",
+	Remove:         "REMOVE",
+	Rename:         "RENAME",
+	ApplyJSONPatch: "APPLY_JSON_PATCH",
+	ApplyTextPatch: "APPLY_TEXT_PATCH",
+}
+
+// String returns the server-side name of the change type.
+func (c ChangeType) String() string {
+	if name, ok := changeTypeNames[c]; ok {
+		return name
+	}
+	return "UNKNOWN"
+}
+
+// MarshalJSON encodes the change type into its server-side name.
+func (c ChangeType) MarshalJSON() ([]byte, error) {
+	name, ok := changeTypeNames[c]
+	if !ok {
+		return nil, fmt.Errorf("centraldogma: unknown change type: %d", int(c))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON decodes the server-side name of the change type.
+func (c *ChangeType) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+	for changeType, changeTypeName := range changeTypeNames {
+		if name == changeTypeName {
+			*c = changeType
+			return nil
+		}
+	}
+	return fmt.Errorf("centraldogma: unknown change type: %q", name)
+}
+
+// Change represents a change to apply to a repository.
+type Change struct {
+	Path    string      `json:"path"`
+	Type    ChangeType  `json:"type"`
+	Content interface{} `json:"content,omitempty"`
+}
+
+// Validate checks that the change is well-formed before it is sent to the
+// server: the path must be set, UpsertJSON content must be structured data
+// that serializes to JSON (a pre-encoded string is almost always a mistake;
+// use json.RawMessage for raw bytes), UpsertText content must be a string
+// and Remove must carry no content. Push validates every change so that a
+// malformed one fails with a clear client-side error instead of an opaque
+// server-side one.
+func (c *Change) Validate() error {
+	if c.Path == "" {
+		return fmt.Errorf("centraldogma: change has no path")
+	}
+	switch c.Type {
+	case UpsertJSON:
+		if _, ok := c.Content.(string); ok {
+			return fmt.Errorf(
+				"centraldogma: UpsertJSON content of %v must be structured data, not a string", c.Path)
+		}
+		if _, err := json.Marshal(c.Content); err != nil {
+			return fmt.Errorf("centraldogma: UpsertJSON content of %v: %v", c.Path, err)
+		}
+	case UpsertText:
+		if _, ok := c.Content.(string); !ok {
+			return fmt.Errorf("centraldogma: UpsertText content of %v must be a string", c.Path)
+		}
+	case Remove:
+		if c.Content != nil {
+			return fmt.Errorf("centraldogma: Remove change of %v must not have content", c.Path)
+		}
+	}
+	return nil
+}
+
+// ListFiles returns the metadata of the files matched by the given path
+// pattern. A path pattern is a variant of glob:
+//
+//   - "/**": find all files recursively
+//   - "*.json": find all JSON files recursively
+//   - "/foo/*.json": find all JSON files under the directory /foo
+//   - "/*/foo.txt": find all files named foo.txt at the second depth level
+//   - "*.json,/bar/*.txt": use comma to match any patterns
+func (c *Client) ListFiles(ctx context.Context, projectName, repoName, revision, pathPattern string) ([]*Entry, error) {
+	return c.content.listFiles(ctx, projectName, repoName, revision, pathPattern, false)
+}
+
+// ListFilesWithMeta returns the metadata of the files matched by the given
+// path pattern including per-entry metadata such as Size, at the cost of a
+// slightly more expensive listing on the server side.
+func (c *Client) ListFilesWithMeta(ctx context.Context, projectName, repoName, revision, pathPattern string) ([]*Entry, error) {
+	return c.content.listFiles(ctx, projectName, repoName, revision, pathPattern, true)
+}
+
+// ListDir returns the metadata of the direct children of the specified
+// directory — files and subdirectories one level deep — which is what a
+// file browser needs, unlike the recursive "/**" listing.
+func (c *Client) ListDir(ctx context.Context, projectName, repoName, revision, dir string) ([]*Entry, error) {
+	dir = ensureLeadingSlash(dir)
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return c.content.listFiles(ctx, projectName, repoName, revision, dir+"*", false)
+}
+
+// ListFilesRecursive returns the metadata of all files under the specified
+// directory, recursively.
+func (c *Client) ListFilesRecursive(ctx context.Context, projectName, repoName, revision, dir string) ([]*Entry, error) {
+	dir = ensureLeadingSlash(dir)
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return c.content.listFiles(ctx, projectName, repoName, revision, dir+"**", false)
+}
+
+// CountFiles returns the number of files matched by the given path pattern,
+// for quota and sanity checks that only need "how many" and not the files
+// themselves. It uses the listing endpoint, so no file content is
+// transferred; directory entries are not counted.
+func (c *Client) CountFiles(ctx context.Context, projectName, repoName, revision, pathPattern string) (int, error) {
+	entries, err := c.content.listFiles(ctx, projectName, repoName, revision, pathPattern, false)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		if entry.Type != Directory {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetFile returns the file at the specified revision and path with the
+// specified query. An empty revision means the head of the repository.
+func (c *Client) GetFile(ctx context.Context, projectName, repoName, revision string, query *Query) (*Entry, error) {
+	return c.content.getFile(ctx, projectName, repoName, revision, query)
+}
+
+// GetLatestFile returns the file at the head of the repository with the
+// specified query. It is equivalent to GetFile with an empty revision but
+// spares the caller the revision-string convention.
+func (c *Client) GetLatestFile(ctx context.Context, projectName, repoName string, query *Query) (*Entry, error) {
+	return c.content.getFile(ctx, projectName, repoName, "", query)
+}
+
+// ErrNotModified is returned by GetFileIfNewerThan when the file has not
+// been modified since the known revision.
+var ErrNotModified = errors.New("centraldogma: file not modified")
+
+// GetFileIfNewerThan returns the file at the specified path only when it was
+// modified after the specified known revision. It returns ErrNotModified
+// when the server reports that the content is unchanged, avoiding a full
+// body transfer.
+func (c *Client) GetFileIfNewerThan(ctx context.Context, projectName, repoName, path string,
+	knownRevision int) (*Entry, error) {
+	return c.content.getFileIfNewerThan(ctx, projectName, repoName, path, knownRevision)
+}
+
+// GetFiles returns the files matched by the given path pattern with their
+// content. Multiple patterns can be joined with a comma, e.g.
+// "/config/**,/secrets/*.json", in which case one request returns the union
+// of the matched files; an entry matched by more than one pattern is
+// returned once. An empty revision means the head of the repository.
+func (c *Client) GetFiles(ctx context.Context, projectName, repoName, revision, pathPattern string) ([]*Entry, error) {
+	return c.content.getFiles(ctx, projectName, repoName, revision, pathPattern)
+}
+
+// GetLatestFiles returns the files matched by the given path pattern at the
+// head of the repository. It is equivalent to GetFiles with an empty
+// revision.
+func (c *Client) GetLatestFiles(ctx context.Context, projectName, repoName, pathPattern string) ([]*Entry, error) {
+	return c.content.getFiles(ctx, projectName, repoName, "", pathPattern)
+}
+
+// GetFilesWithJSONPath returns the JSON files matched by the given path
+// pattern with the specified JSON path expressions applied to the content of
+// each, so that a projection such as "$.version" can be extracted from a
+// whole directory in one call. Non-JSON entries are skipped.
+func (c *Client) GetFilesWithJSONPath(ctx context.Context, projectName, repoName, revision, pathPattern string,
+	expressions []string) ([]*Entry, error) {
+	return c.content.getFilesWithJSONPath(ctx, projectName, repoName, revision, pathPattern, expressions)
+}
+
+// GetHistory returns the history of the files matched by the given path
+// pattern between two revisions. maxCommits limits the number of commits
+// returned; zero means the server default.
+func (c *Client) GetHistory(ctx context.Context, projectName, repoName, from, to, pathPattern string, maxCommits int) ([]*Commit, error) {
+	return c.content.getHistory(ctx, projectName, repoName, from, to, pathPattern, maxCommits)
+}
+
+// LatestCommit returns the most recent commit that touched the specified
+// path, answering "when was this file last changed and by whom" without the
+// range wiring of GetHistory. It returns nil when the path has no history.
+func (c *Client) LatestCommit(ctx context.Context, projectName, repoName, path string) (*Commit, error) {
+	commits, err := c.content.getHistory(ctx, projectName, repoName, "-1", "1", path, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+	return commits[0], nil
+}
+
+// GetDiff returns the diff of a file between two revisions as a Change such
+// as ApplyJSONPatch or ApplyTextPatch.
+func (c *Client) GetDiff(ctx context.Context, projectName, repoName, from, to string, query *Query) (*Change, error) {
+	return c.content.getDiff(ctx, projectName, repoName, from, to, query)
+}
+
+// GetFileBothRevisions fetches the full content of the file matched by the
+// given query at two revisions in parallel, for callers that want both
+// versions side by side rather than the patch GetDiff returns.
+func (c *Client) GetFileBothRevisions(ctx context.Context, projectName, repoName, from, to string,
+	query *Query) (fromEntry, toEntry *Entry, err error) {
+	var wg sync.WaitGroup
+	var fromErr, toErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		fromEntry, fromErr = c.content.getFile(ctx, projectName, repoName, from, query)
+	}()
+	go func() {
+		defer wg.Done()
+		toEntry, toErr = c.content.getFile(ctx, projectName, repoName, to, query)
+	}()
+	wg.Wait()
+
+	if fromErr != nil {
+		return nil, nil, fromErr
+	}
+	if toErr != nil {
+		return nil, nil, toErr
+	}
+	return fromEntry, toEntry, nil
+}
+
+// GetDiffs returns the diffs of the files matched by the given path pattern
+// between two revisions.
+func (c *Client) GetDiffs(ctx context.Context, projectName, repoName, from, to, pathPattern string) ([]*Change, error) {
+	return c.content.getDiffs(ctx, projectName, repoName, from, to, pathPattern)
+}
+
+// ChangedPaths returns the paths of the files touched between two revisions,
+// sorted and deduplicated. It is a lighter alternative to GetDiffs when only
+// the set of modified files matters, e.g. for release tooling.
+func (c *Client) ChangedPaths(ctx context.Context, projectName, repoName, from, to, pathPattern string) ([]string, error) {
+	changes, err := c.content.getDiffs(ctx, projectName, repoName, from, to, pathPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(changes))
+	seen := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if !seen[change.Path] {
+			seen[change.Path] = true
+			paths = append(paths, change.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// EntriesChangedSince returns the current content of the files which were
+// changed after the specified revision, so that an agent which already holds
+// revision sinceRevision can catch up without refetching the whole tree.
+// Files that were removed since then are not part of the result. It returns
+// an empty slice when nothing changed.
+func (c *Client) EntriesChangedSince(ctx context.Context, projectName, repoName string,
+	sinceRevision int, pathPattern string) ([]*Entry, error) {
+	head, err := c.repo.normalizeRevision(ctx, projectName, repoName, "-1")
+	if err != nil {
+		return nil, err
+	}
+	if head <= sinceRevision {
+		return nil, nil
+	}
+
+	paths, err := c.ChangedPaths(ctx, projectName, repoName,
+		strconv.Itoa(sinceRevision), strconv.Itoa(head), pathPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(paths))
+	for _, path := range paths {
+		entry, err := c.content.getFile(ctx, projectName, repoName, strconv.Itoa(head),
+			&Query{Path: path, Type: Identity})
+		if err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				// The file was removed since sinceRevision.
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Push pushes the specified changes to the repository with the specified
+// commit message, based on the specified base revision, and returns the
+// resulting commit as reported by the server, including the new revision
+// and the normalized entries, so that subsequent operations can be chained
+// off the result without refetching.
+func (c *Client) Push(ctx context.Context, projectName, repoName, baseRevision string, commitMessage *CommitMessage, changes []*Change) (*Commit, error) {
+	return c.content.push(ctx, projectName, repoName, baseRevision, commitMessage, changes)
+}
+
+// PushAgainst pushes the specified changes based on the absolute revision
+// the changes were computed from, so that a read-modify-write is explicit
+// about its base: when the head has moved past it in a conflicting way, the
+// server rejects the commit — check for it with IsChangeConflict — instead
+// of silently committing on top of content the caller never saw. Use Pin or
+// NormalizeRevision to obtain the base before reading.
+func (c *Client) PushAgainst(ctx context.Context, projectName, repoName string, base int,
+	commitMessage *CommitMessage, changes []*Change) (*Commit, error) {
+	if base <= 0 {
+		return nil, fmt.Errorf(
+			"centraldogma: PushAgainst requires an absolute base revision, have %v", base)
+	}
+	return c.content.push(ctx, projectName, repoName, strconv.Itoa(base), commitMessage, changes)
+}
+
+func contentsURL(projectName, repoName, path string, v url.Values) string {
+	u := fmt.Sprintf("%v/%v/contents%v", reposURL(projectName), repoName, path)
+	if len(v) != 0 {
+		u += "?" + v.Encode()
+	}
+	return u
+}
+
+func (con *contentService) listFiles(ctx context.Context, projectName, repoName, revision, pathPattern string,
+	withMeta bool) ([]*Entry, error) {
+	v := url.Values{}
+	if revision != "" {
+		v.Set("revision", revision)
+	}
+	if withMeta {
+		v.Set("withMetadata", "true")
+	}
+	u := fmt.Sprintf("%v/%v/list%v", reposURL(projectName), repoName, ensureLeadingSlash(pathPattern))
+	if len(v) != 0 {
+		u += "?" + v.Encode()
+	}
+
+	req, err := con.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if _, err := con.client.do(ctx, req, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (con *contentService) getFile(ctx context.Context, projectName, repoName, revision string, query *Query) (*Entry, error) {
+	if query == nil {
+		return nil, fmt.Errorf("centraldogma: query should not be nil")
+	}
+
+	v := url.Values{}
+	if revision != "" {
+		v.Set("revision", revision)
+	}
+	if query.Type == JSONPath {
+		for _, expr := range query.Expressions {
+			v.Add("jsonpath", expr)
+		}
+	}
+
+	req, err := con.client.newRequest(http.MethodGet,
+		contentsURL(projectName, repoName, ensureLeadingSlash(query.Path), v), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(Entry)
+	if _, err := con.client.do(ctx, req, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (con *contentService) getFileIfNewerThan(ctx context.Context, projectName, repoName, path string,
+	knownRevision int) (*Entry, error) {
+	req, err := con.client.newRequest(http.MethodGet,
+		contentsURL(projectName, repoName, ensureLeadingSlash(path), nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("If-None-Match", strconv.Itoa(knownRevision))
+
+	entry := new(Entry)
+	code, err := con.client.do(ctx, req, entry)
+	if code == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (con *contentService) getFiles(ctx context.Context, projectName, repoName, revision, pathPattern string) ([]*Entry, error) {
+	v := url.Values{}
+	if revision != "" {
+		v.Set("revision", revision)
+	}
+
+	req, err := con.client.newRequest(http.MethodGet,
+		contentsURL(projectName, repoName, normalizePathPattern(pathPattern), v), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if _, err := con.client.do(ctx, req, &entries); err != nil {
+		return nil, err
+	}
+	return dedupeEntriesByPath(entries), nil
+}
+
+// dedupeEntriesByPath drops entries whose path was already seen, keeping the
+// first occurrence. Overlapping comma-joined patterns can match the same
+// file more than once.
+func dedupeEntriesByPath(entries []*Entry) []*Entry {
+	seen := make(map[string]bool, len(entries))
+	deduped := entries[:0]
+	for _, entry := range entries {
+		if seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+		deduped = append(deduped, entry)
+	}
+	return deduped
+}
+
+func (con *contentService) getFilesWithJSONPath(ctx context.Context, projectName, repoName, revision, pathPattern string,
+	expressions []string) ([]*Entry, error) {
+	if len(expressions) == 0 {
+		return nil, fmt.Errorf("centraldogma: at least one JSON path expression is required")
+	}
+
+	v := url.Values{}
+	if revision != "" {
+		v.Set("revision", revision)
+	}
+	for _, expr := range expressions {
+		v.Add("jsonpath", expr)
+	}
+
+	req, err := con.client.newRequest(http.MethodGet,
+		contentsURL(projectName, repoName, normalizePathPattern(pathPattern), v), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if _, err := con.client.do(ctx, req, &entries); err != nil {
+		return nil, err
+	}
+
+	jsonEntries := entries[:0]
+	for _, entry := range dedupeEntriesByPath(entries) {
+		if entry.Type == JSON {
+			jsonEntries = append(jsonEntries, entry)
+		}
+	}
+	return jsonEntries, nil
+}
+
+func (con *contentService) getHistory(ctx context.Context, projectName, repoName, from, to, pathPattern string, maxCommits int) ([]*Commit, error) {
+	v := url.Values{}
+	if to != "" {
+		v.Set("to", to)
+	}
+	if pathPattern != "" {
+		v.Set("path", ensureLeadingSlash(pathPattern))
+	}
+	if maxCommits > 0 {
+		v.Set("maxCommits", strconv.Itoa(maxCommits))
+	}
+	if from == "" {
+		from = "-1"
+	}
+
+	u := fmt.Sprintf("%v/%v/commits/%v", reposURL(projectName), repoName, from)
+	if len(v) != 0 {
+		u += "?" + v.Encode()
+	}
+
+	req, err := con.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*Commit
+	if _, err := con.client.do(ctx, req, &commits); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func (con *contentService) getDiff(ctx context.Context, projectName, repoName, from, to string, query *Query) (*Change, error) {
+	if query == nil {
+		return nil, fmt.Errorf("centraldogma: query should not be nil")
+	}
+
+	v := url.Values{}
+	v.Set("path", ensureLeadingSlash(query.Path))
+	v.Set("from", from)
+	v.Set("to", to)
+	if query.Type == JSONPath {
+		for _, expr := range query.Expressions {
+			v.Add("jsonpath", expr)
+		}
+	}
+
+	u := fmt.Sprintf("%v/%v/compare?%v", reposURL(projectName), repoName, v.Encode())
+	req, err := con.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	change := new(Change)
+	if _, err := con.client.do(ctx, req, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (con *contentService) getDiffs(ctx context.Context, projectName, repoName, from, to, pathPattern string) ([]*Change, error) {
+	if pathPattern == "" {
+		pathPattern = "/**"
+	}
+
+	v := url.Values{}
+	v.Set("pathPattern", ensureLeadingSlash(pathPattern))
+	v.Set("from", from)
+	v.Set("to", to)
+
+	u := fmt.Sprintf("%v/%v/compare?%v", reposURL(projectName), repoName, v.Encode())
+	req, err := con.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*Change
+	if _, err := con.client.do(ctx, req, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (con *contentService) push(ctx context.Context, projectName, repoName, baseRevision string, commitMessage *CommitMessage, changes []*Change) (*Commit, error) {
+	if commitMessage == nil || commitMessage.Summary == "" {
+		return nil, fmt.Errorf("centraldogma: summary of the commit message should not be empty")
+	}
+	if !commitMessage.Markup.valid() {
+		return nil, fmt.Errorf("centraldogma: invalid markup: %q (expected %v or %v)",
+			commitMessage.Markup, Plaintext, Markdown)
+	}
+	// Normalize the markup to what the server accepts: a detail needs one,
+	// a missing detail must not carry one.
+	if commitMessage.Detail != "" && commitMessage.Markup == "" {
+		normalized := *commitMessage
+		normalized.Markup = Plaintext
+		commitMessage = &normalized
+	} else if commitMessage.Detail == "" && commitMessage.Markup != "" {
+		normalized := *commitMessage
+		normalized.Markup = ""
+		commitMessage = &normalized
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("centraldogma: no changes to push")
+	}
+	for _, change := range changes {
+		if err := change.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if baseRevision == "" {
+		baseRevision = "-1"
+	}
+
+	body, err := json.Marshal(&struct {
+		CommitMessage *CommitMessage `json:"commitMessage"`
+		Changes       []*Change      `json:"changes"`
+	}{CommitMessage: commitMessage, Changes: changes})
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	v.Set("revision", baseRevision)
+	u := fmt.Sprintf("%v/%v/contents?%v", reposURL(projectName), repoName, v.Encode())
+
+	req, err := con.client.newRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	commit := new(Commit)
+	if _, err := con.client.do(ctx, req, commit); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// ensureLeadingSlash prepends a slash to the specified path unless it
+// already has one.
+func ensureLeadingSlash(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
@@ -0,0 +1,752 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetFileIfNewerThan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "3" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":3}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Modified since the known revision.
+	entry, err := client.GetFileIfNewerThan(ctx, "foo", "bar", "/a.json", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Revision != 3 || string(entry.Content) != `{"a":1}` {
+		t.Errorf("entry: %+v", entry)
+	}
+
+	// Not modified; no content is transferred.
+	if _, err := client.GetFileIfNewerThan(ctx, "foo", "bar", "/a.json", 3); err != ErrNotModified {
+		t.Errorf("err: %v, want ErrNotModified", err)
+	}
+}
+
+func TestGetFilesCombinedPatterns(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		// "/config/a.json" matches both patterns, so the server reports it twice.
+		fmt.Fprint(w, `[`+
+			`{"path":"/config/a.json","type":"JSON","content":{"a":1},"revision":3},`+
+			`{"path":"/config/a.json","type":"JSON","content":{"a":1},"revision":3},`+
+			`{"path":"/secrets/b.json","type":"JSON","content":{"b":2},"revision":3}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.GetFiles(context.Background(), "foo", "bar", "", "/config/**, secrets/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/api/v1/projects/foo/repos/bar/contents/config/**,/secrets/*.json"; gotPath != want {
+		t.Errorf("request path: %v, want %v", gotPath, want)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries: %v, want 2 deduplicated entries", len(entries))
+	}
+	if entries[0].Path != "/config/a.json" || entries[1].Path != "/secrets/b.json" {
+		t.Errorf("paths: %v, %v", entries[0].Path, entries[1].Path)
+	}
+}
+
+func TestGetLatestFileOmitsRevision(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":7}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := client.GetLatestFile(context.Background(), "foo", "bar",
+		&Query{Path: "/a.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Revision != 7 {
+		t.Errorf("revision: %v, want 7", entry.Revision)
+	}
+	if gotQuery != "" {
+		t.Errorf("query string: %q, want no revision parameter", gotQuery)
+	}
+}
+
+func TestListFilesWithMeta(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `[{"path":"/a.json","type":"JSON","size":128,"revision":3}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.ListFilesWithMeta(context.Background(), "foo", "bar", "-1", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "revision=-1&withMetadata=true" {
+		t.Errorf("query string: %q", gotQuery)
+	}
+	if len(entries) != 1 || entries[0].Size != 128 {
+		t.Errorf("entries: %+v", entries)
+	}
+}
+
+func TestGetFilesWithJSONPath(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `[`+
+			`{"path":"/a.json","type":"JSON","content":"1.0.0","revision":3},`+
+			`{"path":"/notes.txt","type":"TEXT","content":"n","revision":3},`+
+			`{"path":"/b.json","type":"JSON","content":"2.0.0","revision":3}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.GetFilesWithJSONPath(context.Background(), "foo", "bar", "-1", "/**",
+		[]string{"$.version"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "jsonpath=%24.version&revision=-1" {
+		t.Errorf("query string: %q", gotQuery)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries: %v, want the two JSON entries", len(entries))
+	}
+	if string(entries[0].Content) != "1.0.0" || string(entries[1].Content) != "2.0.0" {
+		t.Errorf("contents: %q, %q", entries[0].Content, entries[1].Content)
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"path":"/b.json","type":"UPSERT_JSON","content":{"a":1}},`+
+			`{"path":"/a.txt","type":"UPSERT_TEXT","content":"text"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := client.ChangedPaths(context.Background(), "foo", "bar", "10", "25", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/a.txt", "/b.json"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths: %v, want %v", paths, want)
+	}
+}
+
+func TestChangeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  *Change
+		wantErr string
+	}{
+		{
+			name:    "empty path",
+			change:  &Change{Type: UpsertText, Content: "text"},
+			wantErr: "no path",
+		},
+		{
+			name:    "UpsertJSON with a string",
+			change:  &Change{Path: "/a.json", Type: UpsertJSON, Content: `{"a":1}`},
+			wantErr: "structured data",
+		},
+		{
+			name:    "UpsertJSON with unserializable content",
+			change:  &Change{Path: "/a.json", Type: UpsertJSON, Content: make(chan int)},
+			wantErr: "/a.json",
+		},
+		{
+			name:    "UpsertText with non-string content",
+			change:  &Change{Path: "/a.txt", Type: UpsertText, Content: 42},
+			wantErr: "must be a string",
+		},
+		{
+			name:    "Remove with content",
+			change:  &Change{Path: "/a.txt", Type: Remove, Content: "leftover"},
+			wantErr: "must not have content",
+		},
+		{
+			name:   "valid upsert",
+			change: &Change{Path: "/a.json", Type: UpsertJSON, Content: map[string]int{"a": 1}},
+		},
+		{
+			name:   "valid remove",
+			change: &Change{Path: "/a.txt", Type: Remove},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.change.Validate()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("Validate: %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestPushRejectsInvalidChange(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Push(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "add"},
+		[]*Change{{Path: "/a.json", Type: UpsertJSON, Content: `{"a":1}`}})
+	if err == nil || !strings.Contains(err.Error(), "structured data") {
+		t.Errorf("err: %v, want a validation error", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests: %v, want the push to fail before being sent", requests)
+	}
+}
+
+func TestPushReturnsNormalizedEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server normalizes the pushed JSON (key order, whitespace) and
+		// reports the resulting entries alongside the new revision.
+		fmt.Fprint(w, `{"revision":7,`+
+			`"entries":[`+
+			`{"path":"/a.json","type":"JSON","revision":7,"content":{"a":1,"b":2}},`+
+			`{"path":"/b.txt","type":"TEXT","revision":7,"content":"text\n"}]}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.Push(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "add files"},
+		[]*Change{
+			{Path: "/a.json", Type: UpsertJSON, Content: map[string]int{"b": 2, "a": 1}},
+			{Path: "/b.txt", Type: UpsertText, Content: "text\n"},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if commit.Revision != 7 {
+		t.Errorf("revision: %v, want 7", commit.Revision)
+	}
+	if len(commit.Entries) != 2 {
+		t.Fatalf("entries: %v, want 2", len(commit.Entries))
+	}
+	jsonEntry := commit.Entries[0]
+	if jsonEntry.Path != "/a.json" || jsonEntry.Type != JSON || jsonEntry.Revision != 7 {
+		t.Errorf("JSON entry: %+v", jsonEntry)
+	}
+	if string(jsonEntry.Content) != `{"a":1,"b":2}` {
+		t.Errorf("normalized JSON content: %s", jsonEntry.Content)
+	}
+	textEntry := commit.Entries[1]
+	if textEntry.Path != "/b.txt" || textEntry.Type != Text {
+		t.Errorf("text entry: %+v", textEntry)
+	}
+	if string(textEntry.Content) != "text\n" {
+		t.Errorf("text content: %q", textEntry.Content)
+	}
+}
+
+func TestGetFileBothRevisions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revision := r.URL.Query().Get("revision")
+		fmt.Fprintf(w, `{"path":"/a.json","type":"JSON","revision":%v,`+
+			`"content":{"rev":%v}}`, revision, revision)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &Query{Path: "/a.json", Type: Identity}
+	fromEntry, toEntry, err := client.GetFileBothRevisions(context.Background(),
+		"foo", "bar", "10", "25", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromEntry.Revision != 10 || string(fromEntry.Content) != `{"rev":10}` {
+		t.Errorf("from entry: %+v (content: %s)", fromEntry, fromEntry.Content)
+	}
+	if toEntry.Revision != 25 || string(toEntry.Content) != `{"rev":25}` {
+		t.Errorf("to entry: %+v (content: %s)", toEntry, toEntry.Content)
+	}
+}
+
+func TestListDirListsOnlyDirectChildren(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[{"path":"/configs/a.json","type":"JSON"},`+
+			`{"path":"/configs/sub","type":"DIRECTORY"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.ListDir(context.Background(), "foo", "bar", "", "configs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/api/v1/projects/foo/repos/bar/list/configs/*"; gotPath != want {
+		t.Errorf("request path: %v, want the one-level pattern %v", gotPath, want)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries: %v, want 2", len(entries))
+	}
+	if entries[1].Type != Directory {
+		t.Errorf("second entry type: %v, want Directory", entries[1].Type)
+	}
+}
+
+func TestPushRejectsInvalidMarkup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request was sent although the markup is invalid")
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := &CommitMessage{Summary: "summary", Detail: "detail", Markup: "PLAIN_TEXT"}
+	change := &Change{Path: "/a.txt", Type: UpsertText, Content: "a"}
+	_, err = client.Push(context.Background(), "foo", "bar", "-1", message, []*Change{change})
+	if err == nil || !strings.Contains(err.Error(), "invalid markup") {
+		t.Errorf("err: %v, want a client-side markup validation error", err)
+	}
+}
+
+func TestEntriesChangedSince(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":7}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/compare",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"path":"/a.json","type":"UPSERT_JSON"},{"path":"/gone.txt","type":"REMOVE"}]`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			if got := r.URL.Query().Get("revision"); got != "7" {
+				t.Errorf("revision: %v, want 7", got)
+			}
+			fmt.Fprint(w, `{"revision":7,"path":"/a.json","type":"JSON","content":{"a":2}}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/gone.txt",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.EntriesChangedSince(context.Background(), "foo", "bar", 5, "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Path != "/a.json" {
+		t.Fatalf("entries: %+v, want only /a.json", entries)
+	}
+}
+
+func TestEntriesChangedSinceUpToDate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":5}`)
+		})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %v", r.URL.Path)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.EntriesChangedSince(context.Background(), "foo", "bar", 5, "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("entries: %+v, want none", entries)
+	}
+}
+
+func TestPushNormalizesCommitMessageMarkup(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    *CommitMessage
+		wantMarkup Markup
+	}{
+		{
+			name:       "detail without markup gets plaintext",
+			message:    &CommitMessage{Summary: "summary", Detail: "detail"},
+			wantMarkup: Plaintext,
+		},
+		{
+			name:       "markup without detail is cleared",
+			message:    &CommitMessage{Summary: "summary", Markup: Markdown},
+			wantMarkup: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sent CommitMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := new(struct {
+					CommitMessage CommitMessage `json:"commitMessage"`
+				})
+				if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+					t.Error(err)
+				}
+				sent = body.CommitMessage
+				fmt.Fprint(w, `{"revision":2}`)
+			}))
+			defer server.Close()
+
+			client, err := NewClientWithToken(server.URL, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			change := &Change{Path: "/a.txt", Type: UpsertText, Content: "a"}
+			if _, err := client.Push(context.Background(), "foo", "bar", "-1",
+				test.message, []*Change{change}); err != nil {
+				t.Fatal(err)
+			}
+			if sent.Markup != test.wantMarkup {
+				t.Errorf("markup sent: %q, want %q", sent.Markup, test.wantMarkup)
+			}
+		})
+	}
+}
+
+func TestEntryContentBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   *Entry
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "json is pretty-printed",
+			entry: &Entry{Path: "/a.json", Type: JSON, Content: EntryContent(`{"a":1}`)},
+			want:  "{\n  \"a\": 1\n}\n",
+		},
+		{
+			name:  "text is returned as-is",
+			entry: &Entry{Path: "/b.txt", Type: Text, Content: EntryContent("hello\n")},
+			want:  "hello\n",
+		},
+		{
+			name:  "binary is base64-decoded",
+			entry: &Entry{Path: "/c.bin", Type: Binary, Content: EntryContent("aGVsbG8=")},
+			want:  "hello",
+		},
+		{
+			name:    "directory has no content",
+			entry:   &Entry{Path: "/sub", Type: Directory},
+			wantErr: true,
+		},
+		{
+			name:    "malformed base64 is rejected",
+			entry:   &Entry{Path: "/c.bin", Type: Binary, Content: EntryContent("%%%")},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.entry.ContentBytes()
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("ContentBytes returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("content: %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLatestCommit(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `[{"revision":7,"author":{"name":"alice","email":"alice@example.com"},`+
+			`"commitMessage":{"summary":"Tune limits"}}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.LatestCommit(context.Background(), "foo", "bar", "/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/api/v1/projects/foo/repos/bar/commits/-1" {
+		t.Errorf("path: %q", gotPath)
+	}
+	if gotQuery != "maxCommits=1&path=%2Fa.json&to=1" {
+		t.Errorf("query: %q", gotQuery)
+	}
+	if commit == nil || commit.Revision != 7 || commit.Author.Name != "alice" ||
+		commit.CommitMessage.Summary != "Tune limits" {
+		t.Errorf("commit: %+v", commit)
+	}
+}
+
+func TestLatestCommitNoHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.LatestCommit(context.Background(), "foo", "bar", "/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit != nil {
+		t.Errorf("commit: %+v, want nil for an empty history", commit)
+	}
+}
+
+func TestGetFileJSONPathArrayResult(t *testing.T) {
+	var gotJSONPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotJSONPath = r.URL.Query().Get("jsonpath")
+		// A JSONPath matching multiple nodes yields an array.
+		fmt.Fprint(w, `{"path":"/items.json","type":"JSON","content":["a","b","c"],"revision":3}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := client.GetFile(context.Background(), "foo", "bar", "-1", &Query{
+		Path:        "/items.json",
+		Type:        JSONPath,
+		Expressions: []string{"$.items[*].name"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotJSONPath != "$.items[*].name" {
+		t.Errorf("jsonpath sent: %q", gotJSONPath)
+	}
+	if string(entry.Content) != `["a","b","c"]` {
+		t.Errorf("content: %s, want the array preserved as-is", entry.Content)
+	}
+
+	// The array survives a marshal round-trip unchanged.
+	encoded, err := json.Marshal(entry.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `["a","b","c"]` {
+		t.Errorf("round-tripped content: %s", encoded)
+	}
+}
+
+func TestCountFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/foo/repos/bar/list/configs/*.yaml" {
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"path":"/configs/a.yaml","type":"TEXT"},`+
+			`{"path":"/configs/b.yaml","type":"TEXT"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := client.CountFiles(context.Background(), "foo", "bar", "-1", "/configs/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("count: %v, want 2", count)
+	}
+}
+
+func TestCountFilesSkipsDirectories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"path":"/configs","type":"DIRECTORY"},`+
+			`{"path":"/configs/a.yaml","type":"TEXT"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := client.CountFiles(context.Background(), "foo", "bar", "-1", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("count: %v, want directories excluded", count)
+	}
+}
+
+func TestPushAgainst(t *testing.T) {
+	var revision string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revision = r.URL.Query().Get("revision")
+		fmt.Fprint(w, `{"revision":43}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.PushAgainst(context.Background(), "foo", "bar", 42,
+		&CommitMessage{Summary: "Update a.json"},
+		[]*Change{{Path: "/a.json", Type: UpsertJSON, Content: map[string]int{"a": 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != "42" {
+		t.Errorf("base revision: %v, want 42", revision)
+	}
+	if commit.Revision != 43 {
+		t.Errorf("revision: %v, want 43", commit.Revision)
+	}
+
+	if _, err := client.PushAgainst(context.Background(), "foo", "bar", -1,
+		&CommitMessage{Summary: "Update a.json"},
+		[]*Change{{Path: "/a.json", Type: UpsertJSON, Content: map[string]int{"a": 1}}}); err == nil {
+		t.Error("PushAgainst accepted a relative base revision, want an error")
+	}
+}
+
+func TestPushAgainstConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.ChangeConflictException",`+
+			`"message":"/a.json changed since revision 42"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.PushAgainst(context.Background(), "foo", "bar", 42,
+		&CommitMessage{Summary: "Update a.json"},
+		[]*Change{{Path: "/a.json", Type: UpsertJSON, Content: map[string]int{"a": 1}}})
+	if err == nil {
+		t.Fatal("PushAgainst succeeded, want a conflict")
+	}
+	if !IsChangeConflict(err) {
+		t.Errorf("IsChangeConflict(%v) = false, want true", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CopyRepository copies the head contents of one repository into another,
+// creating the destination repository when it does not exist yet. All files
+// of the source are fetched at head and pushed into the destination as a
+// single commit; directory entries are skipped because pushing their files
+// creates them implicitly. It returns the commit pushed to the destination.
+func (c *Client) CopyRepository(ctx context.Context, srcProject, srcRepo, dstProject, dstRepo string) (*Commit, error) {
+	entries, err := c.GetFiles(ctx, srcProject, srcRepo, "", "/**")
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*Change
+	for _, entry := range entries {
+		change, err := copiedChange(entry)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("centraldogma: %v/%v has no files to copy", srcProject, srcRepo)
+	}
+
+	if err := c.ensureRepository(ctx, dstProject, dstRepo); err != nil {
+		return nil, err
+	}
+
+	message := &CommitMessage{
+		Summary: fmt.Sprintf("Copy %v/%v", srcProject, srcRepo),
+	}
+	return c.Push(ctx, dstProject, dstRepo, "", message, changes)
+}
+
+// copiedChange converts a fetched entry into the upsert change that
+// recreates it, or nil for entry types that need no change of their own.
+func copiedChange(entry *Entry) (*Change, error) {
+	switch entry.Type {
+	case JSON:
+		return &Change{Path: entry.Path, Type: UpsertJSON, Content: json.RawMessage(entry.Content)}, nil
+	case Text:
+		return &Change{Path: entry.Path, Type: UpsertText, Content: string(entry.Content)}, nil
+	case Directory:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("centraldogma: unknown entry type of %v: %v", entry.Path, entry.Type)
+}
+
+// ensureRepository creates the repository unless it already exists.
+func (c *Client) ensureRepository(ctx context.Context, projectName, repoName string) error {
+	repos, err := c.ListRepositories(ctx, projectName)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if repo.Name == repoName {
+			return nil
+		}
+	}
+	_, err = c.CreateRepository(ctx, projectName, repoName)
+	return err
+}
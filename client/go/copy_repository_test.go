@@ -0,0 +1,85 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCopyRepository(t *testing.T) {
+	var createdRepo string
+	var pushed struct {
+		Changes []*Change `json:"changes"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/src/contents"):
+			fmt.Fprint(w, `[{"path":"/a.json","type":"JSON","content":{"a":1}},`+
+				`{"path":"/b.txt","type":"TEXT","content":"text"}]`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/repos"):
+			fmt.Fprint(w, `[{"name":"other"}]`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/repos"):
+			var repo struct {
+				Name string `json:"name"`
+			}
+			json.NewDecoder(r.Body).Decode(&repo)
+			createdRepo = repo.Name
+			fmt.Fprintf(w, `{"name":%q}`, repo.Name)
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/dst/contents"):
+			if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+				t.Error(err)
+			}
+			fmt.Fprint(w, `{"revision":2}`)
+		default:
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.CopyRepository(context.Background(), "foo", "src", "foo", "dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Revision != 2 {
+		t.Errorf("revision: %v, want 2", commit.Revision)
+	}
+	if createdRepo != "dst" {
+		t.Errorf("created repository: %q, want dst", createdRepo)
+	}
+	if len(pushed.Changes) != 2 {
+		t.Fatalf("pushed changes: %v, want 2", len(pushed.Changes))
+	}
+	byPath := map[string]*Change{}
+	for _, change := range pushed.Changes {
+		byPath[change.Path] = change
+	}
+	if change := byPath["/a.json"]; change == nil || change.Type != UpsertJSON {
+		t.Errorf("change for /a.json: %+v", change)
+	}
+	if change := byPath["/b.txt"]; change == nil || change.Type != UpsertText {
+		t.Errorf("change for /b.txt: %+v", change)
+	}
+}
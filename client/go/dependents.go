@@ -0,0 +1,81 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Dependent describes something that references a file: currently a mirror
+// which replicates the subtree the file lives in. Name identifies the
+// referencing configuration and Path is the subtree it covers.
+type Dependent struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// mirrorConfig is the part of a mirror configuration the dependents lookup
+// needs.
+type mirrorConfig struct {
+	ID        string `json:"id"`
+	LocalRepo string `json:"localRepo"`
+	LocalPath string `json:"localPath"`
+}
+
+// FileDependents reports what references the specified path, so that the
+// blast radius of removing or moving a file can be checked first. It
+// currently inspects the mirror configurations of the project; a server
+// without the mirror API yields no dependents rather than an error.
+func (c *Client) FileDependents(ctx context.Context, projectName, repoName, path string) ([]Dependent, error) {
+	u := fmt.Sprintf("%vprojects/%v/mirrors", defaultPathPrefix, projectName)
+	req, err := c.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mirrors []*mirrorConfig
+	if _, err := c.do(ctx, req, &mirrors); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	path = ensureLeadingSlash(path)
+	var dependents []Dependent
+	for _, mirror := range mirrors {
+		if mirror.LocalRepo != repoName {
+			continue
+		}
+		covered := ensureLeadingSlash(mirror.LocalPath)
+		if !strings.HasSuffix(covered, "/") {
+			covered += "/"
+		}
+		if path+"/" == covered || strings.HasPrefix(path, covered) {
+			dependents = append(dependents, Dependent{
+				Type: "mirror",
+				Name: mirror.ID,
+				Path: mirror.LocalPath,
+			})
+		}
+	}
+	return dependents, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFileDependents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/foo/mirrors" {
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id":"mirror-configs","localRepo":"bar","localPath":"/configs/"},`+
+			`{"id":"mirror-other","localRepo":"other","localPath":"/"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependents, err := client.FileDependents(context.Background(), "foo", "bar", "/configs/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents) != 1 {
+		t.Fatalf("dependents: %+v, want only the mirror of this repository", dependents)
+	}
+	if dependents[0].Type != "mirror" || dependents[0].Name != "mirror-configs" ||
+		dependents[0].Path != "/configs/" {
+		t.Errorf("dependent: %+v", dependents[0])
+	}
+
+	// A file outside the mirrored subtree has no dependents.
+	dependents, err = client.FileDependents(context.Background(), "foo", "bar", "/other/b.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dependents) != 0 {
+		t.Errorf("dependents: %+v, want none", dependents)
+	}
+}
+
+func TestFileDependentsWithoutMirrorAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception":"java.lang.IllegalArgumentException","message":"not found"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dependents, err := client.FileDependents(context.Background(), "foo", "bar", "/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dependents != nil {
+		t.Errorf("dependents: %+v, want none when the server has no mirror API", dependents)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// DiffLocal fetches the file at the specified revision and reports whether
+// the given local content is equivalent to it, so that a caller can preview
+// whether a put would change anything. JSON files are compared structurally,
+// so formatting differences do not count. The fetched entry is returned for
+// rendering a diff.
+func (c *Client) DiffLocal(ctx context.Context, projectName, repoName, revision, path string,
+	localContent []byte) (*Entry, bool, error) {
+	entry, err := c.content.getFile(ctx, projectName, repoName, revision,
+		&Query{Path: path, Type: Identity})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if entry.Type == JSON {
+		var remote, local interface{}
+		if json.Unmarshal(entry.Content, &remote) == nil &&
+			json.Unmarshal(localContent, &local) == nil {
+			return entry, reflect.DeepEqual(remote, local), nil
+		}
+		return entry, false, nil
+	}
+	return entry, string(entry.Content) == string(localContent), nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func catCommand() *command {
+	return &command{
+		name:  "cat",
+		usage: "cat [options] <project>/<repository>/<path>",
+		desc:  "Prints the content of a file",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "cat", "cat [options] <project>/<repository>/<path>")
+			revision := flags.String("revision", defaultRevision, "the revision of the file")
+			jsonPath := flags.String("jsonpath", "", "the JSON path expression to apply")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a file path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			if err := pathutil.EnsureFilePath(path); err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+
+			entry, err := client.GetFile(context.Background(), project, repo, *revision,
+				newQuery(path, *jsonPath))
+			if err != nil {
+				return err
+			}
+			return printEntryContent(e, entry)
+		},
+	}
+}
+
+func getCommand() *command {
+	return &command{
+		name:  "get",
+		usage: "get [options] <project>/<repository>/<path>",
+		desc:  "Downloads a file",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "get", "get [options] <project>/<repository>/<path>")
+			revision := flags.String("revision", defaultRevision, "the revision of the file")
+			jsonPath := flags.String("jsonpath", "", "the JSON path expression to apply")
+			output := flags.String("o", "", "the name of the downloaded file")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a file path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			if err := pathutil.EnsureFilePath(path); err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+
+			entry, err := client.GetFile(context.Background(), project, repo, *revision,
+				newQuery(path, *jsonPath))
+			if err != nil {
+				return err
+			}
+
+			name := *output
+			if name == "" {
+				name = filepath.Base(entry.Path)
+			}
+			content, err := entryContentBytes(entry)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(name, content, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "Downloaded: %v\n", name)
+			return nil
+		},
+	}
+}
+
+// newQuery returns an identity query, or a JSON path query when a JSON path
+// expression is given.
+func newQuery(path, jsonPath string) *centraldogma.Query {
+	if jsonPath == "" {
+		return &centraldogma.Query{Path: path, Type: centraldogma.Identity}
+	}
+	return &centraldogma.Query{
+		Path:        path,
+		Type:        centraldogma.JSONPath,
+		Expressions: []string{jsonPath},
+	}
+}
+
+// printEntryContent writes the content of the entry to the output stream.
+func printEntryContent(e *env, entry *centraldogma.Entry) error {
+	content, err := entryContentBytes(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(e.out, string(content))
+	return err
+}
+
+// entryContentBytes returns the displayable content of the entry: indented
+// JSON for a JSON entry and the raw bytes for a text entry.
+func entryContentBytes(entry *centraldogma.Entry) ([]byte, error) {
+	switch entry.Type {
+	case centraldogma.JSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, entry.Content, "", "  "); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case centraldogma.Text:
+		return entry.Content, nil
+	case centraldogma.Directory:
+		return nil, fmt.Errorf("cannot cat a directory: %v (use ls instead)", entry.Path)
+	}
+	return nil, fmt.Errorf("unknown entry type: %v", entry.Type)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCatCommandDirectory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"path":"/a","type":"DIRECTORY"}`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "cat", "foo/bar/a"}, out, errOut); code != 1 {
+		t.Fatalf("exit code: %v, want 1", code)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "cannot cat a directory") {
+		t.Errorf("stderr: %q, want a clear directory error", errOut.String())
+	}
+}
+
+func TestCatCommandJSONPathArray(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("jsonpath"); got != "$.items[*].name" {
+			t.Errorf("jsonpath sent: %q", got)
+		}
+		fmt.Fprint(w, `{"path":"/items.json","type":"JSON","content":["a","b"],"revision":3}`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	code := Run([]string{"-connect", server.URL, "cat",
+		"-jsonpath", "$.items[*].name", "foo/bar/items.json"}, out, errOut)
+	if code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+	// A multi-match result is rendered as a pretty-printed array.
+	if want := "[\n  \"a\",\n  \"b\"\n]\n"; out.String() != want {
+		t.Errorf("output: %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func cloneCommand() *command {
+	return &command{
+		name:  "clone",
+		usage: "clone <project>/<repository> <project>/<repository>",
+		desc:  "Copies the contents of a repository into another",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "clone", "clone <project>/<repository> <project>/<repository>")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if flags.NArg() != 2 {
+				flags.Usage()
+				return fmt.Errorf("a source and a destination repository are required")
+			}
+
+			srcProject, srcRepo, err := splitRepoName(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			dstProject, dstRepo, err := splitRepoName(flags.Arg(1))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			commit, err := client.CopyRepository(context.Background(),
+				srcProject, srcRepo, dstProject, dstRepo)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "Cloned: /%v/%v -> /%v/%v (revision: %v)\n",
+				srcProject, srcRepo, dstProject, dstRepo, commit.Revision)
+			return nil
+		},
+	}
+}
+
+// splitRepoName splits a "<project>/<repository>" argument.
+func splitRepoName(name string) (project, repo string, err error) {
+	segments := strings.Split(strings.Trim(name, "/"), "/")
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("invalid repository name: %v (expected: <project>/<repository>)", name)
+	}
+	return segments[0], segments[1], nil
+}
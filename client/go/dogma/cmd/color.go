@@ -0,0 +1,74 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// The ANSI SGR sequences used for colored output.
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorReset  = "\x1b[0m"
+)
+
+// useColor decides whether output should be colored: an explicit -no-color
+// always wins, the NO_COLOR environment variable disables color, and
+// otherwise color is used only when writing to a terminal, so that piped
+// output and CI logs stay free of escape sequences.
+func useColor(noColor bool, noColorEnv string, terminal bool) bool {
+	return !noColor && noColorEnv == "" && terminal
+}
+
+// isTerminal reports whether the writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the given color when color is enabled for this
+// invocation, and returns it unchanged otherwise.
+func (e *env) colorize(color, s string) string {
+	if !e.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// colorizeDiffLine colors one line of diff output: additions green and
+// removals red, covering both the -/+ prefixes of a line diff and the
+// (added)/(removed) markers of a JSON field diff.
+func (e *env) colorizeDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+") || strings.Contains(line, "(added)"):
+		return e.colorize(colorGreen, line)
+	case strings.HasPrefix(line, "-") || strings.Contains(line, "(removed)"):
+		return e.colorize(colorRed, line)
+	default:
+		return line
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestUseColor(t *testing.T) {
+	tests := []struct {
+		name       string
+		noColor    bool
+		noColorEnv string
+		terminal   bool
+		want       bool
+	}{
+		{name: "terminal", terminal: true, want: true},
+		{name: "piped output", terminal: false, want: false},
+		// -no-color wins even on a terminal.
+		{name: "no-color flag on a terminal", noColor: true, terminal: true, want: false},
+		{name: "NO_COLOR environment", noColorEnv: "1", terminal: true, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := useColor(test.noColor, test.noColorEnv, test.terminal); got != test.want {
+				t.Errorf("useColor(%v, %q, %v) = %v, want %v",
+					test.noColor, test.noColorEnv, test.terminal, got, test.want)
+			}
+		})
+	}
+}
+
+func TestColorizeDiffLine(t *testing.T) {
+	e := &env{color: true}
+	if got := e.colorizeDiffLine("+added"); got != colorGreen+"+added"+colorReset {
+		t.Errorf("added line: %q", got)
+	}
+	if got := e.colorizeDiffLine("-removed"); got != colorRed+"-removed"+colorReset {
+		t.Errorf("removed line: %q", got)
+	}
+	if got := e.colorizeDiffLine(" same"); got != " same" {
+		t.Errorf("context line: %q", got)
+	}
+	e.color = false
+	if got := e.colorizeDiffLine("+added"); got != "+added" {
+		t.Errorf("colorized despite color being disabled: %q", got)
+	}
+}
+
+func TestRunLogNoColor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"revision":3,"commitMessage":{"summary":"Update"}}]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "-no-color",
+		"log", "foo/bar"}, out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Errorf("output contains ANSI escapes despite -no-color: %q", out.String())
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package cmd implements the subcommands of the dogma command line
+// interface.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+// defaultRevision is the revision used when the user did not specify one.
+// It is shared with the legacy CLI so that both binaries send identical
+// requests for the same input.
+const defaultRevision = pathutil.DefaultRevision
+
+// env carries the global options and the output streams of a single
+// invocation.
+type env struct {
+	connect string
+	token   string
+	// login makes the client authenticate with a username and a password
+	// prompted on the terminal instead of a token.
+	login bool
+	// nonInteractive disables everything that would block on the user:
+	// editors and prompts. Mutating commands then require -m.
+	nonInteractive bool
+	// color enables ANSI colors in the output; see useColor for how it is
+	// decided.
+	color bool
+	// ctx is canceled when the invocation is interrupted, so that blocking
+	// subprocesses such as the editor are terminated instead of leaving the
+	// CLI stuck in a wait.
+	ctx    context.Context
+	out    io.Writer
+	errOut io.Writer
+}
+
+// context returns the context of the invocation, which is never nil.
+func (e *env) context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
+}
+
+// command is a single dogma subcommand.
+type command struct {
+	name  string
+	usage string
+	desc  string
+	run   func(e *env, args []string) error
+}
+
+func commands() []*command {
+	return []*command{
+		lsCommand(),
+		treeCommand(),
+		newCommand(),
+		cloneCommand(),
+		catCommand(),
+		getCommand(),
+		putCommand(),
+		rmCommand(),
+		diffCommand(),
+		diffLocalCommand(),
+		logCommand(),
+		normalizeCommand(),
+		dependentsCommand(),
+		exportCommand(),
+		watchCommand(),
+		statusCommand(),
+	}
+}
+
+// Run executes the dogma command line with the specified arguments, writing
+// its output to out and errOut, and returns the exit code of the process.
+func Run(args []string, out, errOut io.Writer) int {
+	flags := flag.NewFlagSet("dogma", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	connect := flags.String("connect", defaultConnect(),
+		"the host or URL of the Central Dogma server")
+	token := flags.String("token", os.Getenv("DOGMA_TOKEN"),
+		"the token to authorize the requests with")
+	login := flags.Bool("login", false,
+		"prompts for a username and a password instead of using a token")
+	var verbose bool
+	flags.BoolVar(&verbose, "verbose", false, "prints request URLs and watch retries")
+	flags.BoolVar(&verbose, "v", false, "shorthand for -verbose")
+	var nonInteractive bool
+	flags.BoolVar(&nonInteractive, "non-interactive", false,
+		"never launches an editor or prompts; mutating commands require -m")
+	flags.BoolVar(&nonInteractive, "yes", false, "shorthand for -non-interactive")
+	noColor := flags.Bool("no-color", false, "disables colored output")
+	flags.Usage = func() { printUsage(errOut) }
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if verbose {
+		centraldogma.SetLogOutput(errOut)
+	}
+
+	rest := flags.Args()
+	if len(rest) == 0 {
+		printUsage(errOut)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	e := &env{connect: *connect, token: *token, login: *login,
+		nonInteractive: nonInteractive,
+		color:          useColor(*noColor, os.Getenv("NO_COLOR"), isTerminal(out)),
+		ctx:            ctx, out: out, errOut: errOut}
+	for _, c := range commands() {
+		if c.name == rest[0] {
+			if err := c.run(e, rest[1:]); err != nil {
+				fmt.Fprintf(errOut, "dogma: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+	}
+
+	fmt.Fprintf(errOut, "dogma: unknown command: %v\n", rest[0])
+	printUsage(errOut)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: dogma [global options] <command> [options] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The commands are:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-10v %v\n", c.name, c.desc)
+	}
+}
+
+func defaultConnect() string {
+	if v := os.Getenv("DOGMA_CONNECT"); v != "" {
+		return v
+	}
+	return "localhost:36462"
+}
+
+// newFlagSet returns a flag set for a subcommand, wired to the error output
+// of the invocation.
+func newFlagSet(e *env, name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.errOut)
+	flags.Usage = func() {
+		fmt.Fprintf(e.errOut, "usage: dogma %v\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// client returns a Central Dogma client for the server the invocation is
+// connected to.
+func (e *env) client() (*centraldogma.Client, error) {
+	if e.login {
+		username, password, err := promptCredentials(e, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return centraldogma.NewClient(e.connect, username, password)
+	}
+	return centraldogma.NewClientWithToken(e.connect, e.token)
+}
+
+// printJSON writes the indented JSON representation of v.
+func printJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.linecorp.com/centraldogma"
+)
+
+const commitMessageTemplate = `
+# Please enter the commit message for your changes. The first non-comment
+# line is the summary and the remaining lines are the detail. Lines starting
+# with '#' will be ignored, and an empty message aborts the commit.
+`
+
+// getCommitMessage returns a commit message with the specified summary, read
+// from the specified message file, or composed in an editor when neither is
+// given. In non-interactive mode an empty message is an error instead.
+func getCommitMessage(e *env, summary, messageFile, defaultSummary string) (*centraldogma.CommitMessage, error) {
+	if summary != "" && messageFile != "" {
+		return nil, fmt.Errorf("-m and -F are mutually exclusive")
+	}
+	if summary != "" {
+		return &centraldogma.CommitMessage{Summary: summary}, nil
+	}
+	if messageFile != "" {
+		return commitMessageFromFile(messageFile)
+	}
+	if e.nonInteractive {
+		return nil, fmt.Errorf("a commit message is required (-m or -F) in non-interactive mode")
+	}
+	return editCommitMessage(e.context(), defaultSummary)
+}
+
+// commitMessageFromFile reads a commit message from the specified file, like
+// git commit -F: the first non-comment line is the summary and the rest is
+// the detail.
+func commitMessageFromFile(path string) (*centraldogma.CommitMessage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return messageFrom(file)
+}
+
+// editCommitMessage launches the user's editor on a temporary file seeded
+// with the default summary and parses the edited result. The editor is
+// killed and a cancellation error returned when the context is canceled
+// while it is open, e.g. by an interrupt.
+func editCommitMessage(ctx context.Context, defaultSummary string) (*centraldogma.CommitMessage, error) {
+	file, err := os.CreateTemp("", "COMMIT_MESSAGE_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(defaultSummary + "\n" + commitMessageTemplate); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.CommandContext(ctx, editor, file.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("editing the commit message was canceled: %v", ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to launch the editor %v: %v", editor, err)
+	}
+
+	edited, err := os.Open(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer edited.Close()
+	return messageFrom(edited)
+}
+
+// messageFrom parses a commit message: the first non-comment, non-empty line
+// becomes the summary and the remaining non-comment lines become the detail.
+func messageFrom(r io.Reader) (*centraldogma.CommitMessage, error) {
+	var summary string
+	var detail []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if summary == "" {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			summary = strings.TrimSpace(line)
+			continue
+		}
+		detail = append(detail, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("empty commit message")
+	}
+
+	commitMessage := &centraldogma.CommitMessage{Summary: summary}
+	if joined := strings.TrimSpace(strings.Join(detail, "\n")); joined != "" {
+		commitMessage.Detail = joined
+		commitMessage.Markup = centraldogma.Plaintext
+	}
+	return commitMessage, nil
+}
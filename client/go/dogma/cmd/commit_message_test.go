@@ -0,0 +1,87 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommitMessageFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "message.txt")
+	content := "# generated by the deploy pipeline\n" +
+		"Update service endpoints\n" +
+		"\n" +
+		"Rolled out by release 1.2.3.\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	message, err := commitMessageFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Summary != "Update service endpoints" {
+		t.Errorf("summary: %q", message.Summary)
+	}
+	if message.Detail != "Rolled out by release 1.2.3." {
+		t.Errorf("detail: %q", message.Detail)
+	}
+	if message.Markup != "PLAINTEXT" {
+		t.Errorf("markup: %q", message.Markup)
+	}
+}
+
+func TestGetCommitMessageRejectsBothFlagSources(t *testing.T) {
+	e := &env{nonInteractive: true}
+	if _, err := getCommitMessage(e, "summary", "message.txt", ""); err == nil {
+		t.Error("getCommitMessage accepted both -m and -F, want an error")
+	}
+}
+
+func TestEditCommitMessageCanceled(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	// An "editor" that never returns, standing in for one the user abandoned.
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\nexec sleep 60\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", editor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := editCommitMessage(ctx, "summary")
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "canceled") {
+			t.Errorf("err: %v, want a cancellation error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("editCommitMessage kept blocking after the context was canceled")
+	}
+}
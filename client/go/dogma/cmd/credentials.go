@@ -0,0 +1,68 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// promptCredentials asks for a username and a password on the terminal. It
+// refuses to prompt when the invocation is non-interactive or when in is not
+// a terminal, so that a scripted run such as a cron job fails fast with an
+// actionable error instead of blocking on input that never comes.
+func promptCredentials(e *env, in *os.File) (username, password string, err error) {
+	if err := ensureInteractive(e, in); err != nil {
+		return "", "", err
+	}
+	reader := bufio.NewReader(in)
+	username, err = promptLine(e, reader, "Username: ")
+	if err != nil {
+		return "", "", err
+	}
+	password, err = promptLine(e, reader, "Password: ")
+	if err != nil {
+		return "", "", err
+	}
+	return username, password, nil
+}
+
+// ensureInteractive reports whether in can be prompted at all.
+func ensureInteractive(e *env, in *os.File) error {
+	if e.nonInteractive {
+		return fmt.Errorf("cannot prompt for credentials in non-interactive mode; " +
+			"use -token or the DOGMA_TOKEN environment variable")
+	}
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("cannot prompt for credentials: standard input is not a terminal; " +
+			"use -token or the DOGMA_TOKEN environment variable")
+	}
+	return nil
+}
+
+func promptLine(e *env, reader *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(e.errOut, prompt)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptCredentialsNonTTY(t *testing.T) {
+	// A regular file is not a character device, like stdin redirected from a
+	// file or a pipe in a cron job.
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	e := &env{out: os.Stdout, errOut: os.Stderr}
+	if _, _, err := promptCredentials(e, in); err == nil {
+		t.Fatal("promptCredentials succeeded on a non-TTY stdin, want an error")
+	} else if !strings.Contains(err.Error(), "-token") {
+		t.Errorf("error: %v, want a hint to use -token", err)
+	}
+}
+
+func TestPromptCredentialsNonInteractive(t *testing.T) {
+	e := &env{nonInteractive: true, out: os.Stdout, errOut: os.Stderr}
+	if _, _, err := promptCredentials(e, os.Stdin); err == nil {
+		t.Fatal("promptCredentials succeeded in non-interactive mode, want an error")
+	} else if !strings.Contains(err.Error(), "non-interactive") {
+		t.Errorf("error: %v, want a mention of non-interactive mode", err)
+	}
+}
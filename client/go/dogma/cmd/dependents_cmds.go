@@ -0,0 +1,65 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+)
+
+func dependentsCommand() *command {
+	return &command{
+		name:  "dependents",
+		usage: "dependents [options] <project>/<repository>/<path>",
+		desc:  "Lists what references a file, such as mirrors",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "dependents",
+				"dependents [options] <project>/<repository>/<path>")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a file path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			dependents, err := client.FileDependents(context.Background(), project, repo, path)
+			if err != nil {
+				return err
+			}
+			if *jsonOut {
+				return printJSON(e.out, dependents)
+			}
+			if len(dependents) == 0 {
+				fmt.Fprintf(e.out, "No dependents of %v\n", path)
+				return nil
+			}
+			for _, dependent := range dependents {
+				fmt.Fprintf(e.out, "%-8v %v (%v)\n", dependent.Type, dependent.Name, dependent.Path)
+			}
+			return nil
+		},
+	}
+}
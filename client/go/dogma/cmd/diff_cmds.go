@@ -0,0 +1,159 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func diffCommand() *command {
+	return &command{
+		name:  "diff",
+		usage: "diff [options] <project>/<repository>[/<path>]",
+		desc:  "Shows the diff between two revisions",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "diff", "diff [options] <project>/<repository>[/<path>]")
+			from := flags.String("from", "1", "the base revision of the diff")
+			to := flags.String("to", defaultRevision, "the target revision of the diff")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(from, to); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a repository path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			if path == "/" || strings.HasSuffix(path, "/") {
+				pattern := path + "**"
+				changes, err := client.GetDiffs(ctx, project, repo, *from, *to, pattern)
+				if err != nil {
+					return err
+				}
+				return printJSON(e.out, changes)
+			}
+
+			// For a JSON file, fetch both documents and render a readable
+			// field-by-field diff instead of dumping the raw patch ops.
+			if strings.HasSuffix(strings.ToLower(path), ".json") {
+				return printJSONFieldDiff(ctx, e, client, project, repo, *from, *to, path)
+			}
+
+			query := &centraldogma.Query{Path: path, Type: centraldogma.Identity}
+			change, err := client.GetDiff(ctx, project, repo, *from, *to, query)
+			if err != nil {
+				return err
+			}
+			return printJSON(e.out, change)
+		},
+	}
+}
+
+func printJSONFieldDiff(ctx context.Context, e *env, client *centraldogma.Client,
+	project, repo, from, to, path string) error {
+	query := &centraldogma.Query{Path: path, Type: centraldogma.Identity}
+	fromEntry, err := client.GetFile(ctx, project, repo, from, query)
+	if err != nil {
+		return err
+	}
+	toEntry, err := client.GetFile(ctx, project, repo, to, query)
+	if err != nil {
+		return err
+	}
+
+	var fromDoc, toDoc interface{}
+	if err := json.Unmarshal(fromEntry.Content, &fromDoc); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(toEntry.Content, &toDoc); err != nil {
+		return err
+	}
+
+	for _, line := range jsonFieldDiffs("$", fromDoc, toDoc) {
+		fmt.Fprintln(e.out, e.colorizeDiffLine(line))
+	}
+	return nil
+}
+
+// jsonFieldDiffs renders the differences between two JSON documents as one
+// line per changed field, e.g. `$.a.b: 1 -> 2`, `$.c: (added) "x"` and
+// `$.d: (removed)`.
+func jsonFieldDiffs(prefix string, from, to interface{}) []string {
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+
+	fromFields, fromOK := from.(map[string]interface{})
+	toFields, toOK := to.(map[string]interface{})
+	if !fromOK || !toOK {
+		return []string{fmt.Sprintf("%v: %v -> %v", prefix, jsonValue(from), jsonValue(to))}
+	}
+
+	keys := make([]string, 0, len(fromFields)+len(toFields))
+	for key := range fromFields {
+		keys = append(keys, key)
+	}
+	for key := range toFields {
+		if _, ok := fromFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		fromValue, inFrom := fromFields[key]
+		toValue, inTo := toFields[key]
+		field := prefix + "." + key
+		switch {
+		case !inFrom:
+			lines = append(lines, fmt.Sprintf("%v: (added) %v", field, jsonValue(toValue)))
+		case !inTo:
+			lines = append(lines, fmt.Sprintf("%v: (removed)", field))
+		default:
+			lines = append(lines, jsonFieldDiffs(field, fromValue, toValue)...)
+		}
+	}
+	return lines
+}
+
+// jsonValue renders a JSON value compactly for a diff line.
+func jsonValue(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONFieldDiffs(t *testing.T) {
+	from := map[string]interface{}{
+		"a": map[string]interface{}{"b": float64(1)},
+		"d": "gone",
+	}
+	to := map[string]interface{}{
+		"a": map[string]interface{}{"b": float64(2)},
+		"c": "x",
+	}
+
+	got := jsonFieldDiffs("$", from, to)
+	want := []string{
+		`$.a.b: 1 -> 2`,
+		`$.c: (added) "x"`,
+		`$.d: (removed)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff lines:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestRunDiffLocalJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"revision":3,"path":"/config.json","type":"JSON","content":{"a":1,"b":"x"}}`)
+	}))
+	defer server.Close()
+
+	local := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(local, []byte(`{"a":2,"b":"x"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "diff-local", "foo/bar/config.json", local},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if got := strings.TrimSpace(out.String()); got != "$.a: 1 -> 2" {
+		t.Errorf("output: %q, want $.a: 1 -> 2", got)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	from := []string{"one", "two", "three"}
+	to := []string{"one", "2", "three", "four"}
+	got := lineDiff(from, to)
+	want := []string{" one", "-two", "+2", " three", "+four"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff: %q, want %q", got, want)
+	}
+}
+
+func TestRunDiffHeadKeyword(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "diff", "-to", "head", "foo/bar"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if !strings.Contains(query, "to=-1") {
+		t.Errorf("query: %v, want -to head forwarded as to=-1", query)
+	}
+}
+
+func TestRunDiffRejectsInvalidRevision(t *testing.T) {
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"diff", "-to", "hed", "foo/bar"}, out, errOut); code != 1 {
+		t.Fatalf("exit code: %v, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "invalid revision") {
+		t.Errorf("stderr: %q, want an invalid revision error", errOut.String())
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func diffLocalCommand() *command {
+	return &command{
+		name:  "diff-local",
+		usage: "diff-local [options] <project>/<repository>/<path> <local file>",
+		desc:  "Shows what putting a local file would change",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "diff-local",
+				"diff-local [options] <project>/<repository>/<path> <local file>")
+			revision := flags.String("revision", defaultRevision, "the revision to diff against")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 2 {
+				flags.Usage()
+				return fmt.Errorf("a repository path and a local file are required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			local, err := os.ReadFile(flags.Arg(1))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			entry, same, err := client.DiffLocal(context.Background(), project, repo,
+				*revision, path, local)
+			if err != nil {
+				return err
+			}
+			if same {
+				return nil
+			}
+
+			// Render a field diff for JSON and a line diff for everything
+			// else, like the diff command does for two revisions.
+			if entry.Type == centraldogma.JSON {
+				var remote, localDoc interface{}
+				if json.Unmarshal(entry.Content, &remote) == nil &&
+					json.Unmarshal(local, &localDoc) == nil {
+					for _, line := range jsonFieldDiffs("$", remote, localDoc) {
+						fmt.Fprintln(e.out, e.colorizeDiffLine(line))
+					}
+					return nil
+				}
+			}
+			for _, line := range lineDiff(splitLines(string(entry.Content)), splitLines(string(local))) {
+				fmt.Fprintln(e.out, e.colorizeDiffLine(line))
+			}
+			return nil
+		},
+	}
+}
+
+// splitLines splits content into lines without a trailing empty element for
+// a final newline.
+func splitLines(content string) []string {
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineDiff renders a simple line-based diff: unchanged lines are prefixed
+// with a space, removed lines with "-" and added lines with "+". It uses a
+// longest-common-subsequence walk, which is fine for config-sized files.
+func lineDiff(from, to []string) []string {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, " "+from[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "-"+from[i])
+			i++
+		default:
+			lines = append(lines, "+"+to[j])
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, "-"+from[i])
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, "+"+to[j])
+	}
+	return lines
+}
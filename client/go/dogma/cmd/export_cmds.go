@@ -0,0 +1,70 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:  "export",
+		usage: "export [options] <project>/<repository> <archive.tar>",
+		desc:  "Exports a repository into a tar archive",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "export", "export [options] <project>/<repository> <archive.tar>")
+			revision := flags.String("revision", defaultRevision, "the revision to export")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 2 {
+				flags.Usage()
+				return fmt.Errorf("a repository path and an archive file are required")
+			}
+
+			project, repo, _, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			archivePath := flags.Arg(1)
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+
+			archive, err := os.Create(archivePath)
+			if err != nil {
+				return err
+			}
+			if err := client.ExportRepository(context.Background(), project, repo,
+				*revision, archive); err != nil {
+				archive.Close()
+				os.Remove(archivePath)
+				return err
+			}
+			if err := archive.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "Exported: %v/%v to %v\n", project, repo, archivePath)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"text/template"
+)
+
+// printFormatted renders each item of a result with the given -format
+// template on its own line. A non-slice result is rendered as a single item.
+func printFormatted(w io.Writer, format string, result interface{}) error {
+	tmpl, err := template.New("format").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid -format template: %v", err)
+	}
+
+	value := reflect.ValueOf(result)
+	if value.Kind() != reflect.Slice {
+		return executeFormat(w, tmpl, result)
+	}
+	for i := 0; i < value.Len(); i++ {
+		if err := executeFormat(w, tmpl, value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeFormat(w io.Writer, tmpl *template.Template, item interface{}) error {
+	if err := tmpl.Execute(w, item); err != nil {
+		return fmt.Errorf("failed to render -format template: %v", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
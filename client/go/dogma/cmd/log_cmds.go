@@ -0,0 +1,232 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.linecorp.com/centraldogma"
+)
+
+const defaultMaxCommits = 10
+
+func logCommand() *command {
+	return &command{
+		name:  "log",
+		usage: "log [options] <project>/<repository>[/<path>]",
+		desc:  "Shows the commit history of a repository",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "log", "log [options] <project>/<repository>[/<path>]")
+			from := flags.String("from", defaultRevision, "the revision to show the history from")
+			to := flags.String("to", "1", "the revision to show the history to")
+			maxCommits := flags.Int("max-commits", defaultMaxCommits, "the maximum number of commits to show")
+			since := flags.String("since", "", "only shows commits pushed at or after this RFC3339 time")
+			until := flags.String("until", "", "only shows commits pushed at or before this RFC3339 time")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			ndjson := flags.Bool("ndjson", false,
+				"streams one JSON commit per line; -max-commits 0 means unlimited")
+			follow := flags.Bool("follow", false,
+				"streams new commits as they are pushed, like tail -f, until interrupted")
+			format := flags.String("format", "",
+				"renders each commit with this Go template instead of the default output")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(from, to); err != nil {
+				return err
+			}
+			if *follow && (*ndjson || *jsonOut || *format != "") {
+				return fmt.Errorf("-follow cannot be combined with -ndjson, -json or -format")
+			}
+			window, err := newTimeWindow(*since, *until)
+			if err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a repository path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			pattern := path
+			if strings.HasSuffix(pattern, "/") {
+				pattern += "**"
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			if *ndjson {
+				return streamCommitsNDJSON(e, client, project, repo, pattern, *maxCommits, window)
+			}
+			commits, err := client.GetHistory(context.Background(), project, repo,
+				*from, *to, pattern, *maxCommits)
+			if err != nil {
+				return err
+			}
+			commits = window.filterCommits(commits)
+
+			if *format != "" {
+				return printFormatted(e.out, *format, commits)
+			}
+			if *jsonOut {
+				return printJSON(e.out, commits)
+			}
+			for i, commit := range commits {
+				if i > 0 {
+					fmt.Fprintln(e.out)
+				}
+				printCommit(e, commit)
+			}
+			if *follow {
+				return followCommits(e, client, project, repo, pattern, len(commits) > 0)
+			}
+			return nil
+		},
+	}
+}
+
+// followCommits prints each commit pushed to the files matched by the
+// pattern as it arrives, like tail -f, until the invocation is interrupted.
+// separate records whether output was already printed, so that the first
+// streamed commit is separated from it the same way the listed commits are
+// from each other.
+func followCommits(e *env, client *centraldogma.Client, project, repo, pattern string,
+	separate bool) error {
+	stream, err := client.CommitStream(e.context(), project, repo, pattern)
+	if err != nil {
+		return err
+	}
+	for commit := range stream {
+		if separate {
+			fmt.Fprintln(e.out)
+		}
+		printCommit(e, commit)
+		separate = true
+	}
+	return nil
+}
+
+// streamCommitsNDJSON pages through the history with a HistoryIterator and
+// emits each commit as one JSON line as it is fetched, so that a pipeline
+// consuming the output processes large histories incrementally. maxCommits
+// bounds the number of emitted commits; zero means unlimited.
+func streamCommitsNDJSON(e *env, client *centraldogma.Client, project, repo, pattern string,
+	maxCommits int, window *timeWindow) error {
+	it := client.HistoryIterator(context.Background(), project, repo, pattern)
+	enc := json.NewEncoder(e.out)
+	emitted := 0
+	for maxCommits <= 0 || emitted < maxCommits {
+		commit, err := it.Next()
+		if err != nil {
+			return err
+		}
+		if commit == nil {
+			return nil
+		}
+		if !window.includes(commit) {
+			continue
+		}
+		if err := enc.Encode(commit); err != nil {
+			return err
+		}
+		emitted++
+	}
+	return nil
+}
+
+// timeWindow is an optional [since, until] filter on the push time of a
+// commit; a zero bound is open.
+type timeWindow struct {
+	since time.Time
+	until time.Time
+}
+
+func newTimeWindow(since, until string) (*timeWindow, error) {
+	window := &timeWindow{}
+	var err error
+	if since != "" {
+		if window.since, err = time.Parse(time.RFC3339, since); err != nil {
+			return nil, fmt.Errorf("invalid -since time: %v", err)
+		}
+	}
+	if until != "" {
+		if window.until, err = time.Parse(time.RFC3339, until); err != nil {
+			return nil, fmt.Errorf("invalid -until time: %v", err)
+		}
+	}
+	return window, nil
+}
+
+// filterCommits drops the commits pushed outside of the window. A commit
+// whose push time is missing or malformed is kept, so that a server not
+// reporting timestamps does not silently hide history.
+func (window *timeWindow) filterCommits(commits []*centraldogma.Commit) []*centraldogma.Commit {
+	if window.since.IsZero() && window.until.IsZero() {
+		return commits
+	}
+	filtered := commits[:0]
+	for _, commit := range commits {
+		if window.includes(commit) {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
+
+// includes reports whether the commit falls inside the window. A commit
+// whose push time is missing or malformed is included.
+func (window *timeWindow) includes(commit *centraldogma.Commit) bool {
+	pushedAt, err := time.Parse(time.RFC3339, commit.PushedAt)
+	if err != nil {
+		return true
+	}
+	if !window.since.IsZero() && pushedAt.Before(window.since) {
+		return false
+	}
+	if !window.until.IsZero() && pushedAt.After(window.until) {
+		return false
+	}
+	return true
+}
+
+// printCommit renders a single commit in the style of git log.
+func printCommit(e *env, commit *centraldogma.Commit) {
+	w := e.out
+	fmt.Fprintln(w, e.colorize(colorYellow, fmt.Sprintf("commit %v", commit.Revision)))
+	if commit.Author != nil {
+		fmt.Fprintf(w, "Author: %v\n", commit.Author)
+	}
+	if commit.PushedAt != "" {
+		fmt.Fprintf(w, "Date:   %v\n", commit.PushedAt)
+	}
+	if commit.CommitMessage != nil {
+		fmt.Fprintf(w, "\n    %v\n", commit.CommitMessage.Summary)
+		if commit.CommitMessage.Detail != "" {
+			fmt.Fprintln(w)
+			for _, line := range strings.Split(commit.CommitMessage.Detail, "\n") {
+				fmt.Fprintf(w, "    %v\n", line)
+			}
+		}
+	}
+}
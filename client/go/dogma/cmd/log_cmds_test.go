@@ -0,0 +1,189 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func TestTimeWindowFilterCommits(t *testing.T) {
+	commits := []*centraldogma.Commit{
+		{Revision: 2, PushedAt: "2021-06-01T00:00:00Z"},
+		{Revision: 3, PushedAt: "2021-06-15T00:00:00Z"},
+		{Revision: 4, PushedAt: "2021-07-01T00:00:00Z"},
+		{Revision: 5}, // no push time reported: always kept
+	}
+
+	window, err := newTimeWindow("2021-06-10T00:00:00Z", "2021-06-20T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered := window.filterCommits(commits)
+	revisions := make([]int, 0, len(filtered))
+	for _, commit := range filtered {
+		revisions = append(revisions, commit.Revision)
+	}
+	if len(revisions) != 2 || revisions[0] != 3 || revisions[1] != 5 {
+		t.Errorf("revisions: %v, want [3 5]", revisions)
+	}
+}
+
+func TestNewTimeWindowRejectsGarbage(t *testing.T) {
+	if _, err := newTimeWindow("yesterday", ""); err == nil {
+		t.Error("newTimeWindow accepted a non-RFC3339 time, want an error")
+	}
+}
+
+func TestRunLogNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"revision":3,"commitMessage":{"summary":"third"}},`+
+			`{"revision":2,"commitMessage":{"summary":"second"}},`+
+			`{"revision":1,"commitMessage":{"summary":"first"}}]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "log", "-ndjson", "-max-commits", "0", "foo/bar"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines: %q, want 3", lines)
+	}
+	for i, line := range lines {
+		commit := new(centraldogma.Commit)
+		if err := json.Unmarshal([]byte(line), commit); err != nil {
+			t.Fatalf("line %v is not valid JSON: %v", i, err)
+		}
+		if commit.Revision != 3-i {
+			t.Errorf("line %v revision: %v, want %v", i, commit.Revision, 3-i)
+		}
+	}
+}
+
+func TestRunLogHeadKeyword(t *testing.T) {
+	var path, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.RawQuery
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "log", "-from", "HEAD", "-to", "head", "foo/bar"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if path != "/api/v1/projects/foo/repos/bar/commits/-1" {
+		t.Errorf("path: %v, want -from HEAD forwarded as the head revision", path)
+	}
+	if !strings.Contains(query, "to=-1") {
+		t.Errorf("query: %v, want -to head forwarded as to=-1", query)
+	}
+}
+
+func TestFollowCommits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":2}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == "2" {
+				fmt.Fprint(w, `{"revision":3,"changedPaths":["/a.json"]}`)
+				return
+			}
+			<-r.Context().Done()
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/commits/3",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"revision":3,"commitMessage":{"summary":"Update a.json"}}]`)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := centraldogma.NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &signallingWriter{written: make(chan struct{}, 16)}
+	e := &env{ctx: ctx, out: out, errOut: os.Stderr}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- followCommits(e, client, "foo", "bar", "/**", false)
+	}()
+
+	deadline := time.After(10 * time.Second)
+	for !strings.Contains(out.String(), "Update a.json") {
+		select {
+		case <-out.written:
+		case <-deadline:
+			t.Fatal("timed out waiting for the streamed commit")
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "commit 3") {
+		t.Errorf("output: %q, want the streamed commit 3 printed", out.String())
+	}
+}
+
+// signallingWriter is a concurrency-safe buffer which signals every write,
+// so that the test can wait for output from another goroutine.
+type signallingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	written chan struct{}
+}
+
+func (w *signallingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	n, err := w.buf.Write(p)
+	w.mu.Unlock()
+	select {
+	case w.written <- struct{}{}:
+	default:
+	}
+	return n, err
+}
+
+func (w *signallingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
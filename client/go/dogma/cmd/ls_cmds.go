@@ -0,0 +1,169 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func lsCommand() *command {
+	return &command{
+		name:  "ls",
+		usage: "ls [options] [<project>[/<repository>[/<path>]]]",
+		desc:  "Lists the projects, repositories or files",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "ls", "ls [options] [<project>[/<repository>[/<path>]]]")
+			revision := flags.String("revision", defaultRevision, "the revision to list")
+			long := flags.Bool("l", false,
+				"prints details: type and size for files, creator and creation time otherwise")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			format := flags.String("format", "",
+				"renders each result item with this Go template instead of the default output")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			rest := flags.Args()
+			if len(rest) == 0 {
+				return lsProjects(ctx, e, client, *long, *jsonOut, *format)
+			}
+
+			arg := rest[0]
+			if !strings.Contains(strings.Trim(arg, "/"), "/") {
+				return lsRepositories(ctx, e, client, strings.Trim(arg, "/"), *long, *jsonOut, *format)
+			}
+
+			project, repo, path, err := SplitPath(arg)
+			if err != nil {
+				return err
+			}
+			return lsFiles(ctx, e, client, project, repo, *revision, path, *long, *jsonOut, *format)
+		},
+	}
+}
+
+func lsProjects(ctx context.Context, e *env, client *centraldogma.Client, long, jsonOut bool,
+	format string) error {
+	projects, err := client.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		return printFormatted(e.out, format, projects)
+	}
+	if jsonOut {
+		return printJSON(e.out, projects)
+	}
+	for _, project := range projects {
+		if long {
+			created, err := project.CreatedAtTime()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "%-20v %-30v %v\n",
+				formatCreated(created), project.CreatorString(), project.Name)
+		} else {
+			fmt.Fprintln(e.out, project.Name)
+		}
+	}
+	return nil
+}
+
+func lsRepositories(ctx context.Context, e *env, client *centraldogma.Client,
+	project string, long, jsonOut bool, format string) error {
+	repos, err := client.ListRepositories(ctx, project)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		return printFormatted(e.out, format, repos)
+	}
+	if jsonOut {
+		return printJSON(e.out, repos)
+	}
+	for _, repo := range repos {
+		if long {
+			created, err := repo.CreatedAtTime()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "%-20v %-30v %v\n",
+				formatCreated(created), repo.CreatorString(), repo.Name)
+		} else {
+			fmt.Fprintln(e.out, repo.Name)
+		}
+	}
+	return nil
+}
+
+// formatCreated renders a creation time for the long listing, or a dash when
+// the server did not report one.
+func formatCreated(created time.Time) string {
+	if created.IsZero() {
+		return "-"
+	}
+	return created.Format(time.RFC3339)
+}
+
+func lsFiles(ctx context.Context, e *env, client *centraldogma.Client,
+	project, repo, revision, path string, long, jsonOut bool, format string) error {
+	// A trailing slash means "the contents of this directory"; the shared
+	// helper keeps both CLIs consistent about it.
+	pattern := pathutil.ListPattern(path)
+
+	var entries []*centraldogma.Entry
+	var err error
+	if long {
+		entries, err = client.ListFilesWithMeta(ctx, project, repo, revision, pattern)
+	} else {
+		entries, err = client.ListFiles(ctx, project, repo, revision, pattern)
+	}
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		return printFormatted(e.out, format, entries)
+	}
+	if jsonOut {
+		return printJSON(e.out, entries)
+	}
+	for _, entry := range entries {
+		path := entry.Path
+		if entry.Type == centraldogma.Directory {
+			path = e.colorize(colorBlue, path)
+		}
+		if long {
+			fmt.Fprintf(e.out, "%-9v %8v %v\n", entry.Type, entry.Size, path)
+		} else {
+			fmt.Fprintln(e.out, path)
+		}
+	}
+	return nil
+}
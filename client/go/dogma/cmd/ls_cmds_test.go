@@ -0,0 +1,142 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLsCommand(t *testing.T) {
+	var gotPath, gotRevision string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotRevision = r.URL.Query().Get("revision")
+		fmt.Fprint(w, `[{"path":"/a/b.json","type":"JSON"},{"path":"/a/c.txt","type":"TEXT"}]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "ls", "foo/bar/a/"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	if want := "/api/v1/projects/foo/repos/bar/list/a/*"; gotPath != want {
+		t.Errorf("request path: %v, want %v", gotPath, want)
+	}
+	if gotRevision != "-1" {
+		t.Errorf("revision: %v, want -1", gotRevision)
+	}
+	if want := "/a/b.json\n/a/c.txt\n"; out.String() != want {
+		t.Errorf("output: %q, want %q", out.String(), want)
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	project, repo, path, err := SplitPath("foo/bar/a/b.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project != "foo" || repo != "bar" || path != "/a/b.json" {
+		t.Errorf("got %v, %v, %v", project, repo, path)
+	}
+
+	// A trailing slash is preserved so that directory semantics are kept.
+	if _, _, path, _ = SplitPath("foo/bar/a/"); path != "/a/" {
+		t.Errorf("path: %v, want /a/", path)
+	}
+	if _, _, path, _ = SplitPath("foo/bar"); path != "/" {
+		t.Errorf("path: %v, want /", path)
+	}
+	if _, _, _, err = SplitPath("foo"); err == nil {
+		t.Error("expected an error for a path without a repository")
+	}
+}
+
+func TestRunLsFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bar","headRevision":42},{"name":"baz","headRevision":7}]`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "ls",
+		"-format", "{{.Name}} {{.HeadRevision}}", "foo"}, out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if got, want := out.String(), "bar 42\nbaz 7\n"; got != want {
+		t.Errorf("output: %q, want %q", got, want)
+	}
+}
+
+func TestRunLsFormatParseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "ls", "-format", "{{.Name", "foo"},
+		io.Discard, errOut); code != 1 {
+		t.Fatalf("exit code: %v, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "invalid -format template") {
+		t.Errorf("stderr: %q, want a template parse error", errOut.String())
+	}
+}
+
+func TestRunLsTrailingSlashSemantics(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{arg: "foo/bar/a/", want: "/api/v1/projects/foo/repos/bar/list/a/*"},
+		{arg: "foo/bar/a", want: "/api/v1/projects/foo/repos/bar/list/a"},
+	}
+	for _, test := range tests {
+		var gotPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			fmt.Fprint(w, `[]`)
+		}))
+
+		out := new(bytes.Buffer)
+		if code := Run([]string{"-connect", server.URL, "ls", test.arg}, out, out); code != 0 {
+			t.Fatalf("ls %v: exit code %v", test.arg, code)
+		}
+		if gotPath != test.want {
+			t.Errorf("ls %v: request path %v, want %v", test.arg, gotPath, test.want)
+		}
+		server.Close()
+	}
+}
+
+func TestRunCatRejectsDirectoryPath(t *testing.T) {
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"cat", "foo/bar/a/"}, out, errOut); code == 0 {
+		t.Fatal("cat accepted a path with a trailing slash")
+	}
+	if !strings.Contains(errOut.String(), "trailing slash") {
+		t.Errorf("stderr: %q, want a mention of the trailing slash rule", errOut.String())
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func newCommand() *command {
+	return &command{
+		name:  "new",
+		usage: "new [options] <project>[/<repository>]",
+		desc:  "Creates a project or a repository",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "new", "new [options] <project>[/<repository>]")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a project or repository name is required")
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			name := strings.Trim(flags.Arg(0), "/")
+			segments := strings.Split(name, "/")
+			var created string
+			switch len(segments) {
+			case 1:
+				if _, err := client.CreateProject(ctx, segments[0]); err != nil {
+					return err
+				}
+				created = "/" + segments[0]
+			case 2:
+				if _, err := client.CreateRepository(ctx, segments[0], segments[1]); err != nil {
+					return err
+				}
+				created = "/" + segments[0] + "/" + segments[1]
+			default:
+				return fmt.Errorf("invalid name: %v (expected: <project>[/<repository>])", flags.Arg(0))
+			}
+			if *jsonOut {
+				return printJSON(e.out, struct {
+					Created string `json:"created"`
+				}{created})
+			}
+			fmt.Fprintf(e.out, "Created: %v\n", created)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRunNewJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"foo"}`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "new", "-json", "foo"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	want := "{\n  \"created\": \"/foo\"\n}\n"
+	if out.String() != want {
+		t.Errorf("output: %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunNewRepositoryDefaultOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"bar"}`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "new", "foo/bar"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if out.String() != "Created: /foo/bar\n" {
+		t.Errorf("output: %q, want %q", out.String(), "Created: /foo/bar\n")
+	}
+}
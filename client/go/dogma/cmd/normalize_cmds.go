@@ -0,0 +1,126 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func normalizeCommand() *command {
+	return &command{
+		name:  "normalize",
+		usage: "normalize [options] <project>/<repository> [<revision>...]",
+		desc:  "Converts relative revisions into absolute revisions",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "normalize",
+				"normalize [options] <project>/<repository> [<revision>...]")
+			revision := flags.String("revision", defaultRevision, "the revision to normalize")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() < 1 {
+				flags.Usage()
+				return fmt.Errorf("a repository path is required")
+			}
+
+			project, repo, _, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			// Revisions may be given as extra arguments or comma lists; without
+			// any, the -revision flag is normalized as before.
+			var revisions []string
+			for _, arg := range flags.Args()[1:] {
+				for _, rev := range strings.Split(arg, ",") {
+					if rev = strings.TrimSpace(rev); rev != "" {
+						revisions = append(revisions, rev)
+					}
+				}
+			}
+			if len(revisions) == 0 {
+				normalized, err := client.NormalizeRevision(ctx, project, repo, *revision)
+				if err != nil {
+					return err
+				}
+				if *jsonOut {
+					return printJSON(e.out, struct {
+						Revision int `json:"revision"`
+					}{normalized})
+				}
+				fmt.Fprintf(e.out, "revision: %v\n", normalized)
+				return nil
+			}
+
+			normalized, err := normalizeAll(ctx, client, project, repo, revisions)
+			if err != nil {
+				return err
+			}
+			if *jsonOut {
+				results := make([]struct {
+					Revision   string `json:"revision"`
+					Normalized int    `json:"normalized"`
+				}, len(revisions))
+				for i, rev := range revisions {
+					results[i].Revision = rev
+					results[i].Normalized = normalized[i]
+				}
+				return printJSON(e.out, results)
+			}
+			for i, rev := range revisions {
+				fmt.Fprintf(e.out, "%v: %v\n", rev, normalized[i])
+			}
+			return nil
+		},
+	}
+}
+
+// normalizeAll resolves the given revisions in parallel, preserving their
+// order. The first failure is returned.
+func normalizeAll(ctx context.Context, client *centraldogma.Client,
+	project, repo string, revisions []string) ([]int, error) {
+	normalized := make([]int, len(revisions))
+	errs := make([]error, len(revisions))
+	var wg sync.WaitGroup
+	for i, rev := range revisions {
+		wg.Add(1)
+		go func(i int, rev string) {
+			defer wg.Done()
+			normalized[i], errs[i] = client.NormalizeRevision(ctx, project, repo, rev)
+		}(i, rev)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return normalized, nil
+}
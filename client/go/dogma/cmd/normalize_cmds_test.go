@@ -0,0 +1,65 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunNormalizeMultipleRevisions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/revision/-1"):
+			fmt.Fprint(w, `{"revision":42}`)
+		case strings.HasSuffix(r.URL.Path, "/revision/-2"):
+			fmt.Fprint(w, `{"revision":41}`)
+		default:
+			t.Errorf("unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL,
+		"normalize", "foo/bar", "-1", "-2"}, out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	if got, want := out.String(), "-1: 42\n-2: 41\n"; got != want {
+		t.Errorf("output: %q, want %q", got, want)
+	}
+}
+
+func TestRunNormalizeJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"revision":42}`)
+	}))
+	defer server.Close()
+
+	out := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "normalize", "-json", "foo/bar"},
+		out, os.Stderr); code != 0 {
+		t.Fatalf("exit code: %v", code)
+	}
+	want := "{\n  \"revision\": 42\n}\n"
+	if out.String() != want {
+		t.Errorf("output: %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func putCommand() *command {
+	return &command{
+		name:  "put",
+		usage: "put [options] <project>/<repository>/<path> <local file>",
+		desc:  "Puts a local file into a repository",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "put", "put [options] <project>/<repository>/<path> <local file>")
+			revision := flags.String("revision", defaultRevision, "the base revision of the commit")
+			message := flags.String("m", "", "the summary of the commit message")
+			var messageFile string
+			flags.StringVar(&messageFile, "F", "", "reads the commit message from the specified file")
+			flags.StringVar(&messageFile, "message-file", "", "shorthand for -F")
+			json5 := flags.Bool("json5", false,
+				"accepts JSON with comments and trailing commas, normalizing it to strict JSON")
+			fileType := flags.String("type", "",
+				"forces the file to be stored as json or text regardless of its extension")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 2 {
+				flags.Usage()
+				return fmt.Errorf("a repository path and a local file are required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			localPath := flags.Arg(1)
+			if strings.HasSuffix(path, "/") {
+				path += filepath.Base(localPath)
+			}
+
+			if *fileType != "" && *fileType != "json" && *fileType != "text" {
+				return fmt.Errorf(`invalid -type: %q (expected "json" or "text")`, *fileType)
+			}
+			change, err := newUpsertChange(localPath, path, *json5, *fileType)
+			if err != nil {
+				return err
+			}
+			commitMessage, err := getCommitMessage(e, *message, messageFile, "Add "+path)
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			commit, err := client.Push(context.Background(), project, repo, *revision,
+				commitMessage, []*centraldogma.Change{change})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "Put: %v (revision: %v)\n", path, commit.Revision)
+			return nil
+		},
+	}
+}
+
+func rmCommand() *command {
+	return &command{
+		name:  "rm",
+		usage: "rm [options] <project>/<repository>/<path>",
+		desc:  "Removes a file from a repository",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "rm", "rm [options] <project>/<repository>/<path>")
+			revision := flags.String("revision", defaultRevision, "the base revision of the commit")
+			message := flags.String("m", "", "the summary of the commit message")
+			var messageFile string
+			flags.StringVar(&messageFile, "F", "", "reads the commit message from the specified file")
+			flags.StringVar(&messageFile, "message-file", "", "shorthand for -F")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a file path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			commitMessage, err := getCommitMessage(e, *message, messageFile, "Remove "+path)
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			change := &centraldogma.Change{Path: path, Type: centraldogma.Remove}
+			commit, err := client.Push(context.Background(), project, repo, *revision,
+				commitMessage, []*centraldogma.Change{change})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(e.out, "Removed: %v (revision: %v)\n", path, commit.Revision)
+			return nil
+		},
+	}
+}
+
+// newUpsertChange builds an upsert change for the specified local file. By
+// default a file whose repository path ends with ".json" is pushed as JSON
+// and everything else as text; fileType ("json" or "text") overrides the
+// detection. When json5 is set, JSON files may contain comments and
+// trailing commas, which are stripped before pushing.
+func newUpsertChange(localPath, repoPath string, json5 bool, fileType string) (*centraldogma.Change, error) {
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lower := strings.ToLower(repoPath)
+	asJSON := strings.HasSuffix(lower, ".json") || (json5 && strings.HasSuffix(lower, ".json5"))
+	switch fileType {
+	case "json":
+		asJSON = true
+	case "text":
+		asJSON = false
+	}
+	if asJSON {
+		if json5 {
+			if content, err = centraldogma.JSON5ToJSON(content); err != nil {
+				return nil, fmt.Errorf("%v: %v", localPath, err)
+			}
+		} else if !json.Valid(content) {
+			return nil, fmt.Errorf("%v is not valid JSON", localPath)
+		}
+		return &centraldogma.Change{
+			Path:    repoPath,
+			Type:    centraldogma.UpsertJSON,
+			Content: json.RawMessage(content),
+		}, nil
+	}
+	return &centraldogma.Change{
+		Path:    repoPath,
+		Type:    centraldogma.UpsertText,
+		Content: string(content),
+	}, nil
+}
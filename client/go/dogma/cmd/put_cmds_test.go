@@ -0,0 +1,130 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.linecorp.com/centraldogma"
+)
+
+func TestPutWithoutMessageNonInteractive(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "a.json")
+	if err := os.WriteFile(localPath, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut bytes.Buffer
+	code := Run([]string{"-non-interactive", "-connect", "localhost:36462",
+		"put", "foo/bar/a.json", localPath}, &out, &errOut)
+	if code != 1 {
+		t.Errorf("exit code: %v, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), "commit message is required") {
+		t.Errorf("stderr does not explain the missing message: %q", errOut.String())
+	}
+}
+
+func TestPutJSON5StoresStrictJSON(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+  // listen port
+  "port": 8080,
+}`
+	if err := os.WriteFile(localPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var pushed struct {
+		Changes []struct {
+			Type    string          `json:"type"`
+			Content json.RawMessage `json:"content"`
+		} `json:"changes"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+				t.Error(err)
+			}
+			fmt.Fprint(w, `{"revision":2}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var out, errOut bytes.Buffer
+	code := Run([]string{"-connect", server.URL,
+		"put", "-m", "add config", "-json5", "foo/bar/config.json", localPath}, &out, &errOut)
+	if code != 0 {
+		t.Fatalf("exit code: %v, stderr: %q", code, errOut.String())
+	}
+
+	if len(pushed.Changes) != 1 {
+		t.Fatalf("changes: %v, want 1", len(pushed.Changes))
+	}
+	if pushed.Changes[0].Type != "UPSERT_JSON" {
+		t.Errorf("change type: %q, want UPSERT_JSON", pushed.Changes[0].Type)
+	}
+	if !json.Valid(pushed.Changes[0].Content) {
+		t.Errorf("pushed content is not valid JSON: %s", pushed.Changes[0].Content)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(pushed.Changes[0].Content, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["port"] != float64(8080) {
+		t.Errorf("port: %v, want 8080", doc["port"])
+	}
+}
+
+func TestNewUpsertChangeTypeOverride(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "service.conf")
+	if err := os.WriteFile(confPath, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "template.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"a":{{placeholder}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := newUpsertChange(confPath, "/service.conf", false, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Type != centraldogma.UpsertJSON {
+		t.Errorf("change type of the forced-JSON file: %v, want UpsertJSON", change.Type)
+	}
+
+	change, err = newUpsertChange(jsonPath, "/template.json", false, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.Type != centraldogma.UpsertText {
+		t.Errorf("change type of the forced-text file: %v, want UpsertText", change.Type)
+	}
+	if change.Content != `{"a":{{placeholder}}}` {
+		t.Errorf("content: %v", change.Content)
+	}
+}
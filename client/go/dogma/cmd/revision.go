@@ -0,0 +1,50 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// normalizeRevisionFlags validates the revision flags given on the command
+// line before they are sent to the server, so that a typo such as "hed"
+// fails with a clear client-side error instead of a server-side one. Each
+// revision must be a non-zero integer (positive absolute or negative
+// relative) or the keyword "head", which is normalized in place to the head
+// revision "-1".
+func normalizeRevisionFlags(revisions ...*string) error {
+	for _, revision := range revisions {
+		normalized, err := normalizeRevision(*revision)
+		if err != nil {
+			return err
+		}
+		*revision = normalized
+	}
+	return nil
+}
+
+func normalizeRevision(revision string) (string, error) {
+	if strings.EqualFold(revision, "head") {
+		return "-1", nil
+	}
+	if n, err := strconv.Atoi(revision); err == nil && n != 0 {
+		return revision, nil
+	}
+	return "", fmt.Errorf(
+		"invalid revision: %q (expected an integer, a negative relative revision or \"head\")",
+		revision)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeRevision(t *testing.T) {
+	tests := []struct {
+		revision string
+		want     string
+		wantErr  bool
+	}{
+		{revision: "3", want: "3"},
+		{revision: "-1", want: "-1"},
+		{revision: "head", want: "-1"},
+		{revision: "HEAD", want: "-1"},
+		{revision: "0", wantErr: true},
+		{revision: "hed", wantErr: true},
+		{revision: "1.5", wantErr: true},
+		{revision: "", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := normalizeRevision(test.revision)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("normalizeRevision(%q) succeeded, want an error", test.revision)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("normalizeRevision(%q): %v", test.revision, err)
+		} else if got != test.want {
+			t.Errorf("normalizeRevision(%q) = %q, want %q", test.revision, got, test.want)
+		}
+	}
+}
+
+func TestCatRejectsInvalidRevision(t *testing.T) {
+	var out, errOut bytes.Buffer
+	code := Run([]string{"cat", "-revision", "hed", "foo/bar/baz.json"}, &out, &errOut)
+	if code != 1 {
+		t.Errorf("exit code: %v, want 1", code)
+	}
+	if !strings.Contains(errOut.String(), `invalid revision: "hed"`) {
+		t.Errorf("stderr: %q, want an invalid-revision error", errOut.String())
+	}
+}
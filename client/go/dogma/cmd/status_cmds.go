@@ -0,0 +1,135 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.linecorp.com/centraldogma"
+)
+
+// repositoryStatus is the summary printed by the status command.
+type repositoryStatus struct {
+	Project      string               `json:"project"`
+	Repository   string               `json:"repository"`
+	HeadRevision int                  `json:"headRevision"`
+	FileCount    int                  `json:"fileCount"`
+	CreatedAt    string               `json:"createdAt,omitempty"`
+	Creator      string               `json:"creator,omitempty"`
+	LastCommit   *centraldogma.Commit `json:"lastCommit,omitempty"`
+}
+
+func statusCommand() *command {
+	return &command{
+		name:  "status",
+		usage: "status [options] <project>/<repository>",
+		desc:  "Shows a summary of a repository",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "status", "status [options] <project>/<repository>")
+			jsonOut := flags.Bool("json", false, "prints the result in JSON")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a repository path is required")
+			}
+
+			project, repo, _, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			ctx := context.Background()
+
+			head, err := client.NormalizeRevision(ctx, project, repo, defaultRevision)
+			if err != nil {
+				return err
+			}
+
+			commits, err := client.GetHistory(ctx, project, repo,
+				defaultRevision, defaultRevision, "/**", 1)
+			if err != nil {
+				return err
+			}
+			var lastCommit *centraldogma.Commit
+			if len(commits) != 0 {
+				lastCommit = commits[0]
+			}
+
+			entries, err := client.ListFiles(ctx, project, repo, strconv.Itoa(head), "/**")
+			if err != nil {
+				return err
+			}
+
+			status := &repositoryStatus{
+				Project:      project,
+				Repository:   repo,
+				HeadRevision: head,
+				FileCount:    len(entries),
+				LastCommit:   lastCommit,
+			}
+			repos, err := client.ListRepositories(ctx, project)
+			if err != nil {
+				return err
+			}
+			for _, r := range repos {
+				if r.Name == repo {
+					status.CreatedAt = r.CreatedAt
+					status.Creator = r.CreatorString()
+					break
+				}
+			}
+			if *jsonOut {
+				return printJSON(e.out, status)
+			}
+			printStatus(e, status)
+			return nil
+		},
+	}
+}
+
+func printStatus(e *env, status *repositoryStatus) {
+	fmt.Fprintf(e.out, "Project:       %v\n", status.Project)
+	fmt.Fprintf(e.out, "Repository:    %v\n", status.Repository)
+	fmt.Fprintf(e.out, "Head revision: %v\n", status.HeadRevision)
+	fmt.Fprintf(e.out, "Files:         %v\n", status.FileCount)
+	if status.CreatedAt != "" || status.Creator != "" {
+		fmt.Fprintf(e.out, "Created:       %v", status.CreatedAt)
+		if status.Creator != "" {
+			fmt.Fprintf(e.out, " by %v", status.Creator)
+		}
+		fmt.Fprintln(e.out)
+	}
+	if commit := status.LastCommit; commit != nil {
+		fmt.Fprint(e.out, "Last commit:  ")
+		if commit.Author != nil {
+			fmt.Fprintf(e.out, " %v", commit.Author)
+		}
+		if commit.PushedAt != "" {
+			fmt.Fprintf(e.out, " at %v", commit.PushedAt)
+		}
+		fmt.Fprintln(e.out)
+		if commit.CommitMessage != nil {
+			fmt.Fprintf(e.out, "\n    %v\n", commit.CommitMessage.Summary)
+		}
+	}
+}
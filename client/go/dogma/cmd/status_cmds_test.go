@@ -0,0 +1,113 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newStatusTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"bar",`+
+				`"creator":{"name":"bob","email":"bob@example.com"},`+
+				`"createdAt":"2021-05-01T00:00:00Z"}]`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":42}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/commits/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"revision":42,`+
+				`"author":{"name":"alice","email":"alice@example.com"},`+
+				`"pushedAt":"2021-06-01T00:00:00Z",`+
+				`"commitMessage":{"summary":"Edit the config"}}]`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/list/",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"path":"/a.json","type":"JSON"},`+
+				`{"path":"/b.txt","type":"TEXT"},`+
+				`{"path":"/c","type":"DIRECTORY"}]`)
+		})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStatusCommand(t *testing.T) {
+	server := newStatusTestServer(t)
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "status", "foo/bar"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	printed := out.String()
+	for _, want := range []string{
+		"Project:       foo",
+		"Repository:    bar",
+		"Head revision: 42",
+		"Files:         3",
+		"alice <alice@example.com>",
+		"2021-06-01T00:00:00Z",
+		"Created:       2021-05-01T00:00:00Z by bob <bob@example.com>",
+		"Edit the config",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("output does not contain %q:\n%v", want, printed)
+		}
+	}
+}
+
+func TestStatusCommandJSON(t *testing.T) {
+	server := newStatusTestServer(t)
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "status", "-json", "foo/bar"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	status := new(repositoryStatus)
+	if err := json.Unmarshal(out.Bytes(), status); err != nil {
+		t.Fatalf("failed to decode the output: %v\n%v", err, out.String())
+	}
+	if status.Project != "foo" || status.Repository != "bar" {
+		t.Errorf("project/repository: %v/%v, want foo/bar", status.Project, status.Repository)
+	}
+	if status.HeadRevision != 42 {
+		t.Errorf("head revision: %v, want 42", status.HeadRevision)
+	}
+	if status.FileCount != 3 {
+		t.Errorf("file count: %v, want 3", status.FileCount)
+	}
+	if status.LastCommit == nil || status.LastCommit.Author == nil ||
+		status.LastCommit.Author.Name != "alice" {
+		t.Errorf("last commit: %+v, want author alice", status.LastCommit)
+	}
+	if status.Creator != "bob <bob@example.com>" || status.CreatedAt != "2021-05-01T00:00:00Z" {
+		t.Errorf("creator: %q, createdAt: %q", status.Creator, status.CreatedAt)
+	}
+}
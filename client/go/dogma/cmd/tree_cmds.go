@@ -0,0 +1,130 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.linecorp.com/centraldogma"
+)
+
+// treeNode is a node of the hierarchy printed by the tree command.
+type treeNode struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type,omitempty"`
+	Children []*treeNode `json:"children,omitempty"`
+}
+
+func treeCommand() *command {
+	return &command{
+		name:  "tree",
+		usage: "tree [options] <project>/<repository>[/<path>]",
+		desc:  "Prints the files of a repository as a tree",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "tree", "tree [options] <project>/<repository>[/<path>]")
+			revision := flags.String("revision", defaultRevision, "the revision to list")
+			depth := flags.Int("depth", 0, "the maximum depth to descend to (0 for unlimited)")
+			jsonOut := flags.Bool("json", false, "prints the nested structure in JSON")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if err := normalizeRevisionFlags(revision); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a repository path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			entries, err := client.ListFilesRecursive(context.Background(), project, repo, *revision, path)
+			if err != nil {
+				return err
+			}
+
+			root := buildTree(path, entries)
+			if *jsonOut {
+				return printJSON(e.out, root)
+			}
+			fmt.Fprintln(e.out, root.Name)
+			renderTree(e.out, root, "", 1, *depth)
+			return nil
+		},
+	}
+}
+
+// buildTree arranges the flat entry listing into a hierarchy rooted at the
+// specified directory.
+func buildTree(dir string, entries []*centraldogma.Entry) *treeNode {
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	root := &treeNode{Name: dir, Type: "DIRECTORY"}
+	for _, entry := range entries {
+		segments := strings.Split(strings.TrimPrefix(entry.Path, dir), "/")
+		node := root
+		for i, segment := range segments {
+			if segment == "" {
+				continue
+			}
+			child := node.child(segment)
+			if child == nil {
+				child = &treeNode{Name: segment, Type: "DIRECTORY"}
+				if i == len(segments)-1 {
+					child.Type = entry.Type.String()
+				}
+				node.Children = append(node.Children, child)
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+func (n *treeNode) child(name string) *treeNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// renderTree prints the children of the node with branch glyphs, descending
+// at most maxDepth levels (0 for unlimited).
+func renderTree(w io.Writer, node *treeNode, prefix string, depth, maxDepth int) {
+	if maxDepth > 0 && depth > maxDepth {
+		return
+	}
+	for i, child := range node.Children {
+		glyph, childPrefix := "├── ", prefix+"│   "
+		if i == len(node.Children)-1 {
+			glyph, childPrefix = "└── ", prefix+"    "
+		}
+		fmt.Fprintf(w, "%v%v%v\n", prefix, glyph, child.Name)
+		renderTree(w, child, childPrefix, depth+1, maxDepth)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTreeTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"path":"/a/x.json","type":"JSON"},`+
+			`{"path":"/a/b/y.txt","type":"TEXT"},`+
+			`{"path":"/c.json","type":"JSON"}]`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTreeCommand(t *testing.T) {
+	server := newTreeTestServer(t)
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "tree", "foo/bar"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	want := `/
+├── a
+│   ├── x.json
+│   └── b
+│       └── y.txt
+└── c.json
+`
+	if out.String() != want {
+		t.Errorf("rendered tree:\n%v\nwant:\n%v", out.String(), want)
+	}
+}
+
+func TestTreeCommandDepth(t *testing.T) {
+	server := newTreeTestServer(t)
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	if code := Run([]string{"-connect", server.URL, "tree", "-depth", "1", "foo/bar"}, out, errOut); code != 0 {
+		t.Fatalf("exit code: %v (stderr: %v)", code, errOut.String())
+	}
+
+	want := `/
+├── a
+└── c.json
+`
+	if out.String() != want {
+		t.Errorf("rendered tree:\n%v\nwant:\n%v", out.String(), want)
+	}
+}
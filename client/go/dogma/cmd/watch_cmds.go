@@ -0,0 +1,162 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"go.linecorp.com/centraldogma"
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+func watchCommand() *command {
+	return &command{
+		name:  "watch",
+		usage: "watch [options] <project>/<repository>/<path>",
+		desc:  "Watches a file and prints its content when it is changed",
+		run: func(e *env, args []string) error {
+			flags := newFlagSet(e, "watch", "watch [options] <project>/<repository>/<path>")
+			jsonPath := flags.String("jsonpath", "", "the JSON path expression to apply")
+			execCmd := flags.String("exec", "",
+				"the command to run on each update; the content is written to a "+
+					"temporary file whose path is passed as the first argument")
+			debounce := flags.Duration("debounce", time.Second,
+				"how long to wait for further updates before running the -exec command")
+			fromRevision := flags.Int("from", 0,
+				"reports the next change after this revision instead of the current content")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("a file path is required")
+			}
+
+			project, repo, path, err := SplitPath(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			if err := pathutil.EnsureFilePath(path); err != nil {
+				return err
+			}
+
+			client, err := e.client()
+			if err != nil {
+				return err
+			}
+			var watcher *centraldogma.Watcher
+			if *fromRevision > 0 {
+				builder := client.NewWatcherBuilder(project, repo).
+					ForFile(newQuery(path, *jsonPath)).
+					StartFrom(*fromRevision)
+				watcher, err = builder.Build()
+			} else {
+				watcher, err = client.FileWatcher(project, repo, newQuery(path, *jsonPath))
+			}
+			if err != nil {
+				return err
+			}
+			defer watcher.Close()
+
+			var updates chan []byte
+			if *execCmd != "" {
+				updates = make(chan []byte, 1)
+				go runWatchExec(*execCmd, *debounce, updates, watcher.Closed(), e.errOut)
+			}
+
+			watcher.Watch(func(revision int, value interface{}) {
+				fmt.Fprintf(e.out, "Watcher noticed an update: %v/%v%v (revision: %v)\n",
+					project, repo, path, revision)
+				content := contentBytes(value)
+				fmt.Fprintln(e.out, string(content))
+				if updates != nil {
+					// Keep only the newest pending content.
+					select {
+					case <-updates:
+					default:
+					}
+					updates <- content
+				}
+			})
+
+			interrupted := make(chan os.Signal, 1)
+			signal.Notify(interrupted, os.Interrupt)
+			<-interrupted
+			return nil
+		},
+	}
+}
+
+// contentBytes renders a watched value: JSON values are indented and string
+// values are emitted as-is.
+func contentBytes(value interface{}) []byte {
+	if text, ok := value.(string); ok {
+		return []byte(text)
+	}
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return []byte(fmt.Sprint(value))
+	}
+	return b
+}
+
+// runWatchExec consumes updates and runs the specified command for each,
+// debouncing rapid successions so that only the newest content triggers a
+// run once the interval has been quiet.
+func runWatchExec(command string, debounce time.Duration, updates <-chan []byte,
+	done <-chan struct{}, errOut io.Writer) {
+	var pending []byte
+	var timer <-chan time.Time
+	for {
+		select {
+		case content := <-updates:
+			pending = content
+			timer = time.After(debounce)
+		case <-timer:
+			timer = nil
+			if err := execWithContent(command, pending, errOut); err != nil {
+				fmt.Fprintf(errOut, "dogma: -exec failed: %v\n", err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// execWithContent writes the content to a temporary file and runs the
+// command with the file path as its first argument.
+func execWithContent(command string, content []byte, errOut io.Writer) error {
+	file, err := os.CreateTemp("", "dogma_watch_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+
+	cmd := exec.Command("sh", "-c", command+` "$1"`, "sh", file.Name())
+	cmd.Stdout = errOut
+	cmd.Stderr = errOut
+	return cmd.Run()
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunWatchExecDebounces(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "invocations")
+	// Each invocation appends one line holding the received content.
+	scriptPath := filepath.Join(dir, "reload.sh")
+	script := "cat \"$1\" >> " + logPath + "\necho >> " + logPath + "\n"
+	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	command := "sh " + scriptPath
+
+	updates := make(chan []byte, 1)
+	done := make(chan struct{})
+	defer close(done)
+	errOut := new(syncBuffer)
+	go runWatchExec(command, 100*time.Millisecond, updates, done, errOut)
+
+	// Two rapid updates: only the newest should trigger the command.
+	updates <- []byte("first")
+	updates <- []byte("second")
+
+	deadline := time.Now().Add(5 * time.Second)
+	var content []byte
+	for {
+		content, _ = os.ReadFile(logPath)
+		if bytes.HasSuffix(content, []byte("\n")) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := string(content); got != "second\n" {
+		t.Errorf("invocations: %q, want one run with the newest content", got)
+	}
+	if got := errOut.String(); got != "" {
+		t.Errorf("stderr: %q", got)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to write from the exec goroutine while
+// the test reads it.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
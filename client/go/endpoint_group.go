@@ -0,0 +1,177 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// unhealthyAfterFailures is how many consecutive failed health checks mark
+// an endpoint unhealthy. A single blip does not take a replica out of
+// rotation.
+const unhealthyAfterFailures = 2
+
+// Ping checks that the server behind the client is reachable and healthy
+// using its health check endpoint.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := c.newRequest(http.MethodGet, "monitor/l7check", nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, req, nil)
+	return err
+}
+
+// EndpointGroup tracks the health of a set of replica endpoints by pinging
+// each on an interval, comparable to Armeria's health-checked endpoint
+// group. Requests of a client constructed with WithEndpointGroup are routed
+// round-robin across the endpoints that are currently healthy. An endpoint
+// is taken out of rotation after consecutive failed checks and put back
+// once a check succeeds again. Close stops the health checking.
+type EndpointGroup struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+
+	client   *http.Client
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// endpoint is one replica and its health state.
+type endpoint struct {
+	url      *url.URL
+	failures int
+	healthy  bool
+}
+
+// NewEndpointGroup returns an endpoint group over the specified endpoints,
+// checking the health of each on the specified interval. The first round of
+// checks runs before returning, so the group starts with an accurate view.
+func NewEndpointGroup(checkInterval time.Duration, endpoints ...string) (*EndpointGroup, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("centraldogma: no endpoints specified")
+	}
+
+	group := &EndpointGroup{
+		client: &http.Client{Timeout: 10 * time.Second},
+		stopCh: make(chan struct{}),
+	}
+	for _, e := range endpoints {
+		normalized, err := normalizeURL(e, defaultScheme)
+		if err != nil {
+			return nil, err
+		}
+		group.endpoints = append(group.endpoints, &endpoint{url: normalized, healthy: true})
+	}
+
+	group.checkAll()
+	go group.checkLoop(checkInterval)
+	return group, nil
+}
+
+// Close stops the periodic health checks.
+func (g *EndpointGroup) Close() {
+	g.stopOnce.Do(func() { close(g.stopCh) })
+}
+
+func (g *EndpointGroup) checkLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			g.checkAll()
+		case <-g.stopCh:
+			return
+		}
+	}
+}
+
+// checkAll pings every endpoint once and updates its health state.
+func (g *EndpointGroup) checkAll() {
+	for _, e := range g.endpoints {
+		healthy := g.check(e.url)
+
+		g.mu.Lock()
+		if healthy {
+			e.failures = 0
+			e.healthy = true
+		} else {
+			e.failures++
+			if e.failures >= unhealthyAfterFailures {
+				e.healthy = false
+			}
+		}
+		g.mu.Unlock()
+	}
+}
+
+// check pings a single endpoint's health check path.
+func (g *EndpointGroup) check(endpointURL *url.URL) bool {
+	u, err := endpointURL.Parse("monitor/l7check")
+	if err != nil {
+		return false
+	}
+	res, err := g.client.Get(u.String())
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+	return res.StatusCode >= 200 && res.StatusCode < 300
+}
+
+// pick returns the next healthy endpoint, round-robin.
+func (g *EndpointGroup) pick() (*url.URL, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := 0; i < len(g.endpoints); i++ {
+		e := g.endpoints[(g.next+i)%len(g.endpoints)]
+		if e.healthy {
+			g.next = (g.next + i + 1) % len(g.endpoints)
+			return e.url, nil
+		}
+	}
+	return nil, fmt.Errorf("centraldogma: no healthy endpoints")
+}
+
+// endpointTransport routes each request to a healthy endpoint of the group.
+type endpointTransport struct {
+	group *EndpointGroup
+	base  http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := t.group.pick()
+	if err != nil {
+		return nil, err
+	}
+	routed := req.Clone(req.Context())
+	routed.URL.Scheme = target.Scheme
+	routed.URL.Host = target.Host
+	routed.Host = ""
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(routed)
+}
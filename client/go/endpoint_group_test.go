@@ -0,0 +1,99 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/monitor/l7check" {
+			t.Errorf("path: %v, want the health check endpoint", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+}
+
+func TestEndpointGroupRoutesAroundUnhealthyReplica(t *testing.T) {
+	healthyRequests := 0
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			healthyRequests++
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer healthy.Close()
+
+	unhealthyRequests := 0
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			unhealthyRequests++
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	group, err := NewEndpointGroup(10*time.Millisecond, unhealthy.URL, healthy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer group.Close()
+
+	// Wait until the repeated check failures take the replica out of
+	// rotation.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		group.mu.Lock()
+		out := !group.endpoints[0].healthy
+		group.mu.Unlock()
+		if out || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client, err := NewClientWithToken(healthy.URL, "", WithEndpointGroup(group))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := client.ListProjects(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if healthyRequests != 4 {
+		t.Errorf("requests to the healthy replica: %v, want 4", healthyRequests)
+	}
+	if unhealthyRequests != 0 {
+		t.Errorf("requests to the unhealthy replica: %v, want 0", unhealthyRequests)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEntryMarshalJSONDirectory(t *testing.T) {
+	entry := &Entry{Path: "/a", Type: Directory}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "content") {
+		t.Errorf("a directory entry should not emit a content field: %v", string(b))
+	}
+
+	// Even when a bogus content slipped in, a directory entry must not
+	// emit it.
+	entry.Content = EntryContent("{}")
+	if b, err = json.Marshal(entry); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "content") {
+		t.Errorf("a directory entry should not emit a content field: %v", string(b))
+	}
+}
+
+func TestEntryMarshalJSONRoundTrip(t *testing.T) {
+	b, err := json.Marshal(&Entry{Path: "/a.json", Type: JSON, Content: EntryContent(`{"a":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(Entry)
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != JSON || string(decoded.Content) != `{"a":1}` {
+		t.Errorf("decoded entry: %+v", decoded)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrRepositoryRemoved and ErrProjectRemoved report that the target of an
+// operation exists but was soft-removed, so the operation can succeed after
+// an unremove. Check for them with errors.Is; the error returned by the
+// client is still the full *APIError.
+var (
+	ErrRepositoryRemoved = errors.New("centraldogma: repository was removed; unremove it first")
+	ErrProjectRemoved    = errors.New("centraldogma: project was removed; unremove it first")
+)
+
+// APIError is an error response from the Central Dogma server.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Exception is the server-side exception type from the JSON error
+	// body, when present.
+	Exception string
+	// Message is the human-readable message from the JSON error body, when
+	// present.
+	Message string
+	// Body is a snapshot of the first few kilobytes of the response body, so
+	// that a non-JSON explanation (e.g. an HTML page from a proxy) still
+	// reaches the caller even though the underlying body is closed.
+	Body string
+}
+
+// Error renders the status code and, when the server provided one, its
+// message, so that the reason of a failure reaches the user.
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("centraldogma: %v %v: status: %v: %v",
+			e.Method, e.URL, e.StatusCode, e.Message)
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("centraldogma: %v %v: status: %v: %v",
+			e.Method, e.URL, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("centraldogma: %v %v: status: %v", e.Method, e.URL, e.StatusCode)
+}
+
+// Is matches the sentinel errors of this package, so that a caller can write
+// errors.Is(err, ErrRepositoryRemoved) instead of parsing the server-side
+// exception name and message out of the APIError itself. The server reports
+// an operation on a soft-removed target as a not-found error whose message
+// names it removed.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRepositoryRemoved:
+		return strings.Contains(e.Exception, "RepositoryNotFoundException") &&
+			strings.Contains(e.Message, "removed")
+	case ErrProjectRemoved:
+		return strings.Contains(e.Exception, "ProjectNotFoundException") &&
+			strings.Contains(e.Message, "removed")
+	}
+	return false
+}
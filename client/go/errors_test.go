@@ -0,0 +1,102 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemovedRepositoryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.RepositoryNotFoundException",`+
+			`"message":"repository: foo/bar (removed)"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetFile(context.Background(), "foo", "bar",
+		"", &Query{Path: "/a.json", Type: Identity})
+	if err == nil {
+		t.Fatal("GetFile on a removed repository succeeded, want an error")
+	}
+	if !errors.Is(err, ErrRepositoryRemoved) {
+		t.Errorf("errors.Is(%v, ErrRepositoryRemoved) = false, want true", err)
+	}
+	if errors.Is(err, ErrProjectRemoved) {
+		t.Errorf("errors.Is(%v, ErrProjectRemoved) = true, want false", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("error is %T, want the *APIError still available", err)
+	}
+}
+
+func TestRemovedProjectError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.ProjectNotFoundException",`+
+			`"message":"project: foo (removed)"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.ListRepositories(context.Background(), "foo")
+	if err == nil {
+		t.Fatal("ListRepositories of a removed project succeeded, want an error")
+	}
+	if !errors.Is(err, ErrProjectRemoved) {
+		t.Errorf("errors.Is(%v, ErrProjectRemoved) = false, want true", err)
+	}
+}
+
+func TestPlainNotFoundIsNotRemoved(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.RepositoryNotFoundException",`+
+			`"message":"repository: foo/bar"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetFile(context.Background(), "foo", "bar",
+		"", &Query{Path: "/a.json", Type: Identity})
+	if err == nil {
+		t.Fatal("GetFile succeeded, want an error")
+	}
+	if errors.Is(err, ErrRepositoryRemoved) {
+		t.Errorf("errors.Is(%v, ErrRepositoryRemoved) = true for a repository that never existed", err)
+	}
+}
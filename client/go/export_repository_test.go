@@ -0,0 +1,72 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExportRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"path":"/a.json","type":"JSON","content":{"a":1}},`+
+			`{"path":"/sub","type":"DIRECTORY"},`+
+			`{"path":"/sub/b.txt","type":"TEXT","content":"hello\n"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := client.ExportRepository(context.Background(), "foo", "bar", "-1", &archive); err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string)
+	tr := tar.NewReader(&archive)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+
+	if got, want := files["a.json"], "{\n  \"a\": 1\n}\n"; got != want {
+		t.Errorf("a.json: %q, want pretty-printed JSON %q", got, want)
+	}
+	if got := files["sub/b.txt"]; got != "hello\n" {
+		t.Errorf("sub/b.txt: %q", got)
+	}
+	if _, ok := files["sub/"]; !ok {
+		t.Errorf("archive entries: %v, want a sub/ directory", files)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// maxVersionFetches bounds how many revisions GetFileVersions fetches
+// concurrently, so that a long revision list does not flood the server.
+const maxVersionFetches = 4
+
+// GetFileVersions returns the content of the file at each of the specified
+// revisions, keyed by revision. The revisions are fetched in parallel with
+// bounded concurrency; the first failure aborts the remaining fetches and is
+// returned.
+func (c *Client) GetFileVersions(ctx context.Context, projectName, repoName, path string,
+	revisions []int) (map[int]*Entry, error) {
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("centraldogma: no revisions specified")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	entries := make(map[int]*Entry, len(revisions))
+	semaphore := make(chan struct{}, maxVersionFetches)
+
+	for _, revision := range revisions {
+		wg.Add(1)
+		go func(revision int) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
+
+			query := &Query{Path: path, Type: Identity}
+			entry, err := c.GetFile(ctx, projectName, repoName, strconv.Itoa(revision), query)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			entries[revision] = entry
+		}(revision)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return entries, nil
+}
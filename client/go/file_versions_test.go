@@ -0,0 +1,77 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetFileVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revision := r.URL.Query().Get("revision")
+		fmt.Fprintf(w, `{"path":"/a.json","type":"JSON","revision":%v,`+
+			`"content":{"version":%v}}`, revision, revision)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.GetFileVersions(context.Background(), "foo", "bar", "/a.json",
+		[]int{3, 5, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries: %v, want 3", len(entries))
+	}
+	for _, revision := range []int{3, 5, 8} {
+		entry := entries[revision]
+		if entry == nil {
+			t.Fatalf("no entry for revision %v", revision)
+		}
+		want := fmt.Sprintf(`{"version":%v}`, revision)
+		if string(entry.Content) != want {
+			t.Errorf("revision %v content: %s, want %v", revision, entry.Content, want)
+		}
+	}
+}
+
+func TestGetFileVersionsPropagatesError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("revision") == "5" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{}}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetFileVersions(context.Background(), "foo", "bar", "/a.json",
+		[]int{3, 5}); err == nil {
+		t.Error("GetFileVersions succeeded, want the 404 to propagate")
+	}
+}
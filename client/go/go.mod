@@ -0,0 +1,3 @@
+module go.linecorp.com/centraldogma
+
+go 1.21
@@ -0,0 +1,95 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"strconv"
+)
+
+// defaultHistoryWindow is the number of commits fetched per request by a
+// HistoryIterator.
+const defaultHistoryWindow = 100
+
+// HistoryIterator lazily pages through the history of a repository from the
+// newest commit to the oldest, so that arbitrarily long histories can be
+// processed without loading them at once.
+type HistoryIterator struct {
+	// WindowSize is the number of commits fetched per request. It must not
+	// be changed after the first call to Next.
+	WindowSize int
+
+	client      *Client
+	ctx         context.Context
+	projectName string
+	repoName    string
+	pathPattern string
+
+	buffer []*Commit
+	from   string
+	done   bool
+}
+
+// HistoryIterator returns an iterator over the history of the files matched
+// by the given path pattern, from the newest commit to the oldest.
+func (c *Client) HistoryIterator(ctx context.Context, projectName, repoName, pathPattern string) *HistoryIterator {
+	return &HistoryIterator{
+		WindowSize:  defaultHistoryWindow,
+		client:      c,
+		ctx:         ctx,
+		projectName: projectName,
+		repoName:    repoName,
+		pathPattern: pathPattern,
+		from:        "-1",
+	}
+}
+
+// Next returns the next commit, fetching the next window from the server
+// when the current one is exhausted. It returns nil when the history is
+// fully consumed.
+func (it *HistoryIterator) Next() (*Commit, error) {
+	if len(it.buffer) == 0 && !it.done {
+		if err := it.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	if len(it.buffer) == 0 {
+		return nil, nil
+	}
+	commit := it.buffer[0]
+	it.buffer = it.buffer[1:]
+	return commit, nil
+}
+
+func (it *HistoryIterator) fetch() error {
+	commits, err := it.client.GetHistory(it.ctx, it.projectName, it.repoName,
+		it.from, "1", it.pathPattern, it.WindowSize)
+	if err != nil {
+		return err
+	}
+	it.buffer = commits
+	if len(commits) == 0 {
+		it.done = true
+		return nil
+	}
+
+	oldest := commits[len(commits)-1].Revision
+	if oldest <= 1 {
+		it.done = true
+		return nil
+	}
+	it.from = strconv.Itoa(oldest - 1)
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestHistoryIterator(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		from := strings.TrimPrefix(r.URL.Path, "/api/v1/projects/foo/repos/bar/commits/")
+		newest := 5
+		if from != "-1" {
+			var err error
+			if newest, err = strconv.Atoi(from); err != nil {
+				t.Errorf("from: %v", from)
+			}
+		}
+		max, _ := strconv.Atoi(r.URL.Query().Get("maxCommits"))
+
+		var commits []string
+		for revision := newest; revision >= 1 && len(commits) < max; revision-- {
+			commits = append(commits, fmt.Sprintf(`{"revision":%v}`, revision))
+		}
+		fmt.Fprintf(w, "[%v]", strings.Join(commits, ","))
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := client.HistoryIterator(context.Background(), "foo", "bar", "/**")
+	it.WindowSize = 3
+
+	var revisions []int
+	for {
+		commit, err := it.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit == nil {
+			break
+		}
+		revisions = append(revisions, commit.Revision)
+	}
+
+	if want := []int{5, 4, 3, 2, 1}; len(revisions) != len(want) {
+		t.Fatalf("revisions: %v, want %v", revisions, want)
+	} else {
+		for i := range want {
+			if revisions[i] != want[i] {
+				t.Fatalf("revisions: %v, want %v", revisions, want)
+			}
+		}
+	}
+	if requests != 2 {
+		t.Errorf("requests: %v, want 2 windows", requests)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ImportTar reads a tar archive, such as one written by ExportRepository,
+// and pushes its files into the repository as a single commit, creating the
+// repository when it does not exist yet. A file ending in ".json" becomes a
+// JSON upsert and everything else a text upsert; directory entries are
+// skipped because pushing their files creates them implicitly. It returns
+// the commit pushed to the repository.
+func (c *Client) ImportTar(ctx context.Context, projectName, repoName, baseRevision string,
+	commitMessage *CommitMessage, r io.Reader) (*Commit, error) {
+	var changes []*Change
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("centraldogma: unsupported tar entry type of %v: %v",
+				header.Name, header.Typeflag)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		change, err := importedChange(header.Name, content)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("centraldogma: the archive has no files to import")
+	}
+
+	if err := c.ensureRepository(ctx, projectName, repoName); err != nil {
+		return nil, err
+	}
+	return c.content.push(ctx, projectName, repoName, baseRevision, commitMessage, changes)
+}
+
+// importedChange converts one archive file into the upsert change that
+// recreates it.
+func importedChange(name string, content []byte) (*Change, error) {
+	path := "/" + strings.TrimPrefix(name, "/")
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		if !json.Valid(content) {
+			return nil, fmt.Errorf("centraldogma: %v is not valid JSON", path)
+		}
+		return &Change{Path: path, Type: UpsertJSON, Content: json.RawMessage(content)}, nil
+	}
+	return &Change{Path: path, Type: UpsertText, Content: string(content)}, nil
+}
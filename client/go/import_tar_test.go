@@ -0,0 +1,85 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImportTar(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	writeTarFile := func(name, content string) {
+		t.Helper()
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTarFile("a.json", `{"a":1}`)
+	writeTarFile("sub/b.txt", "hello\n")
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var pushed struct {
+		Changes []*Change `json:"changes"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bar"}]`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+			t.Error(err)
+		}
+		fmt.Fprint(w, `{"revision":2}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.ImportTar(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "Restore backup"}, &archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Revision != 2 {
+		t.Errorf("revision: %v, want 2", commit.Revision)
+	}
+
+	if len(pushed.Changes) != 2 {
+		t.Fatalf("changes: %+v, want 2", pushed.Changes)
+	}
+	if pushed.Changes[0].Path != "/a.json" || pushed.Changes[0].Type != UpsertJSON {
+		t.Errorf("first change: %+v", pushed.Changes[0])
+	}
+	if pushed.Changes[1].Path != "/sub/b.txt" || pushed.Changes[1].Type != UpsertText {
+		t.Errorf("second change: %+v", pushed.Changes[1])
+	}
+}
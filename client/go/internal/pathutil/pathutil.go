@@ -0,0 +1,127 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package pathutil provides the path and revision handling shared by the
+// command line interfaces, so that the same input always produces the same
+// request regardless of which binary is built.
+package pathutil
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRevision is the revision the CLIs use when the user did not specify
+// one. The server normalizes it to the head revision.
+const DefaultRevision = "-1"
+
+// SplitPath splits an argument of the form "project/repository[/path]" into
+// the project name, the repository name and the path in the repository. The
+// path defaults to "/" and keeps its trailing slash when present.
+func SplitPath(arg string) (project, repo, path string, err error) {
+	segments := strings.Split(strings.TrimPrefix(arg, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", "",
+			fmt.Errorf("invalid path: %v (expected: <project>/<repository>[/<path>])", arg)
+	}
+	project = segments[0]
+	repo = segments[1]
+	path = "/" + strings.Join(segments[2:], "/")
+	return project, repo, path, nil
+}
+
+// ListPattern converts a repository path into the pattern a listing sends.
+// A trailing slash means "the contents of this directory" and becomes
+// "<path>*", matching the direct children; a path without one is sent as-is
+// and matches exactly the named entry. Both CLIs share this rule so that
+// "/foo" and "/foo/" behave the same everywhere.
+func ListPattern(path string) string {
+	if strings.HasSuffix(path, "/") {
+		return path + "*"
+	}
+	return path
+}
+
+// CompilePathPattern compiles a path pattern into a regular expression with
+// the semantics the server documents: "**" matches across directories, "*"
+// within a single one, a pattern without a leading slash matches at any
+// depth, and a comma joins alternative patterns. Features that filter paths
+// client-side share this compiler so that they agree with the server about
+// what a pattern matches.
+func CompilePathPattern(pattern string) (*regexp.Regexp, error) {
+	var alternatives []string
+	for _, alternative := range strings.Split(pattern, ",") {
+		alternative = strings.TrimSpace(alternative)
+		if alternative == "" {
+			continue
+		}
+		if !strings.HasPrefix(alternative, "/") {
+			// A relative pattern such as "*.json" matches at any depth.
+			alternative = "/**/" + alternative
+		}
+		alternatives = append(alternatives, globToRegexp(alternative))
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("empty path pattern: %q", pattern)
+	}
+	return regexp.Compile("^(?:" + strings.Join(alternatives, "|") + ")$")
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); {
+		switch {
+		case strings.HasPrefix(pattern[i:], "/**/"):
+			// "/**/" spans zero or more directories, so "/**/a.json" also
+			// matches "/a.json" at the top level.
+			b.WriteString("/(?:.*/)?")
+			i += len("/**/")
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i += 2
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+			i++
+		default:
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			i++
+		}
+	}
+	return b.String()
+}
+
+// MatchPathPattern reports whether the path matches the path pattern. An
+// invalid or empty pattern matches nothing.
+func MatchPathPattern(pattern, path string) bool {
+	re, err := CompilePathPattern(pattern)
+	if err != nil {
+		return false
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return re.MatchString(path)
+}
+
+// EnsureFilePath rejects a path with a trailing slash for commands that
+// operate on a single file, where the "directory contents" meaning of the
+// slash cannot apply.
+func EnsureFilePath(path string) error {
+	if strings.HasSuffix(path, "/") {
+		return fmt.Errorf(
+			"invalid file path: %v (a trailing slash means a directory; specify a file)", path)
+	}
+	return nil
+}
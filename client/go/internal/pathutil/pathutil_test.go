@@ -0,0 +1,118 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package pathutil
+
+import "testing"
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		arg     string
+		project string
+		repo    string
+		path    string
+		wantErr bool
+	}{
+		{arg: "foo/bar", project: "foo", repo: "bar", path: "/"},
+		{arg: "/foo/bar", project: "foo", repo: "bar", path: "/"},
+		{arg: "foo/bar/a/b.json", project: "foo", repo: "bar", path: "/a/b.json"},
+		{arg: "foo/bar/a/", project: "foo", repo: "bar", path: "/a/"},
+		{arg: "foo", wantErr: true},
+		{arg: "foo//a", wantErr: true},
+		{arg: "", wantErr: true},
+	}
+	for _, test := range tests {
+		project, repo, path, err := SplitPath(test.arg)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("SplitPath(%q) should have failed", test.arg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SplitPath(%q): %v", test.arg, err)
+			continue
+		}
+		if project != test.project || repo != test.repo || path != test.path {
+			t.Errorf("SplitPath(%q) = %v, %v, %v, want %v, %v, %v",
+				test.arg, project, repo, path, test.project, test.repo, test.path)
+		}
+	}
+}
+
+func TestListPattern(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/a/", want: "/a/*"},
+		{path: "/a", want: "/a"},
+		{path: "/", want: "/*"},
+	}
+	for _, test := range tests {
+		if got := ListPattern(test.path); got != test.want {
+			t.Errorf("ListPattern(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestEnsureFilePath(t *testing.T) {
+	if err := EnsureFilePath("/a/b.json"); err != nil {
+		t.Errorf("EnsureFilePath(/a/b.json): %v", err)
+	}
+	if err := EnsureFilePath("/a/"); err == nil {
+		t.Error("EnsureFilePath accepted a trailing slash")
+	}
+}
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// "/**": find all files recursively.
+		{"/**", "/a.json", true},
+		{"/**", "/foo/bar/c.txt", true},
+		// "*.json": find all JSON files recursively.
+		{"*.json", "/a.json", true},
+		{"*.json", "/foo/bar/b.json", true},
+		{"*.json", "/a.txt", false},
+		// "/foo/*.json": find all JSON files under the directory /foo.
+		{"/foo/*.json", "/foo/a.json", true},
+		{"/foo/*.json", "/foo/bar/a.json", false},
+		{"/foo/*.json", "/a.json", false},
+		// "/*/foo.txt": find all files named foo.txt at the second depth level.
+		{"/*/foo.txt", "/bar/foo.txt", true},
+		{"/*/foo.txt", "/foo.txt", false},
+		{"/*/foo.txt", "/a/b/foo.txt", false},
+		// "*.json,/bar/*.txt": use comma to match any patterns.
+		{"*.json,/bar/*.txt", "/foo/a.json", true},
+		{"*.json,/bar/*.txt", "/bar/b.txt", true},
+		{"*.json,/bar/*.txt", "/baz/b.txt", false},
+		// "/**" spans zero directories too.
+		{"/foo/**/a.json", "/foo/a.json", true},
+		{"/foo/**/a.json", "/foo/bar/baz/a.json", true},
+		// A path without a leading slash is normalized before matching.
+		{"/foo/*.json", "foo/a.json", true},
+		// An empty pattern matches nothing.
+		{"", "/a.json", false},
+	}
+	for _, test := range tests {
+		if got := MatchPathPattern(test.pattern, test.path); got != test.want {
+			t.Errorf("MatchPathPattern(%q, %q) = %v, want %v",
+				test.pattern, test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON5ToJSON converts a commented-JSON document to strict JSON by stripping
+// line ("//") and block ("/* */") comments and trailing commas, so that a
+// JSON5-flavored file can be pushed as a structured UpsertJSON change. Other
+// JSON5 extensions such as unquoted keys or single-quoted strings are not
+// supported; the result is verified to be strict JSON.
+func JSON5ToJSON(content []byte) ([]byte, error) {
+	stripped := stripJSONComments(content)
+	normalized := stripTrailingCommas(stripped)
+	if !json.Valid(normalized) {
+		return nil, fmt.Errorf("centraldogma: not valid JSON after removing comments")
+	}
+	return normalized, nil
+}
+
+// stripJSONComments replaces comments outside of string literals with
+// spaces, preserving the offsets of the remaining tokens.
+func stripJSONComments(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		switch {
+		case inString:
+			if out[i] == '\\' {
+				i++
+			} else if out[i] == '"' {
+				inString = false
+			}
+		case out[i] == '"':
+			inString = true
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for i < len(out) && out[i] != '\n' {
+				out[i] = ' '
+				i++
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			for i < len(out) {
+				done := out[i] == '*' && i+1 < len(out) && out[i+1] == '/'
+				out[i] = ' '
+				if done {
+					out[i+1] = ' '
+					i++
+					break
+				}
+				i++
+			}
+		}
+	}
+	return out
+}
+
+// stripTrailingCommas removes commas that are directly followed, ignoring
+// whitespace, by a closing bracket or brace.
+func stripTrailingCommas(content []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(content); i++ {
+		b := content[i]
+		if inString {
+			out = append(out, b)
+			if b == '\\' && i+1 < len(content) {
+				out = append(out, content[i+1])
+				i++
+			} else if b == '"' {
+				inString = false
+			}
+			continue
+		}
+		if b == '"' {
+			inString = true
+		} else if b == ',' {
+			j := i + 1
+			for j < len(content) && isJSONSpace(content[j]) {
+				j++
+			}
+			if j < len(content) && (content[j] == ']' || content[j] == '}') {
+				continue
+			}
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
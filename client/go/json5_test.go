@@ -0,0 +1,59 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSON5ToJSON(t *testing.T) {
+	content := []byte(`{
+  // the port the service listens on
+  "port": 8080,
+  /* hosts are tried
+     in order */
+  "hosts": ["a", "b",],
+  "url": "http://example.com/", // not a comment inside the string
+}`)
+
+	normalized, err := JSON5ToJSON(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(normalized) {
+		t.Fatalf("result is not valid JSON: %s", normalized)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(normalized, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"port":  float64(8080),
+		"hosts": []interface{}{"a", "b"},
+		"url":   "http://example.com/",
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("document: %v, want %v", doc, want)
+	}
+}
+
+func TestJSON5ToJSONRejectsGarbage(t *testing.T) {
+	if _, err := JSON5ToJSON([]byte(`{port: 8080}`)); err == nil {
+		t.Error("JSON5ToJSON accepted unquoted keys, want an error")
+	}
+}
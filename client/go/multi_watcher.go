@@ -0,0 +1,157 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MultiWatcher watches several files of one repository and exposes their
+// most recent values as one combined snapshot, for applications that need a
+// set of config files together rather than one at a time. A MultiWatcher is
+// safe for concurrent use.
+type MultiWatcher struct {
+	watchers map[string]*Watcher // keyed by the file path
+
+	// readyCh is closed once every underlying watcher has its initial
+	// value, so that no combined notification ever carries a partially
+	// initialized snapshot.
+	readyCh   chan struct{}
+	closedCh  chan struct{}
+	closeOnce sync.Once
+
+	listeners      []func(snapshot map[string]Latest)
+	listenersMutex sync.Mutex
+}
+
+// WatchFiles returns a started MultiWatcher which watches the files matched
+// by the given queries. The combined listeners are first notified when all
+// files have their initial value, and then whenever any of them changes.
+func (c *Client) WatchFiles(ctx context.Context, projectName, repoName string,
+	queries []*Query) (*MultiWatcher, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("centraldogma: queries should not be empty")
+	}
+
+	m := &MultiWatcher{
+		watchers: make(map[string]*Watcher, len(queries)),
+		readyCh:  make(chan struct{}),
+		closedCh: make(chan struct{}),
+	}
+	for _, query := range queries {
+		if query == nil {
+			m.close()
+			return nil, fmt.Errorf("centraldogma: query should not be nil")
+		}
+		path := ensureLeadingSlash(query.Path)
+		if _, ok := m.watchers[path]; ok {
+			m.close()
+			return nil, fmt.Errorf("centraldogma: duplicate watched path: %v", path)
+		}
+		w, err := c.FileWatcher(projectName, repoName, query)
+		if err != nil {
+			m.close()
+			return nil, err
+		}
+		m.watchers[path] = w
+		w.Watch(func(revision int, value interface{}) {
+			select {
+			case <-m.readyCh:
+				m.notifyListeners()
+			default:
+				// Still gathering initial values; the first combined
+				// notification covers this update.
+			}
+		})
+	}
+
+	go m.awaitInitialValues(ctx)
+	return m, nil
+}
+
+// awaitInitialValues waits until every underlying watcher has its initial
+// value and then delivers the first combined snapshot.
+func (m *MultiWatcher) awaitInitialValues(ctx context.Context) {
+	for _, w := range m.watchers {
+		select {
+		case <-w.initialValueCh:
+		case <-ctx.Done():
+			m.Close()
+			return
+		case <-m.closedCh:
+			return
+		}
+	}
+	close(m.readyCh)
+	m.notifyListeners()
+}
+
+// Latest returns the most recent value of every watched file, keyed by its
+// path. A file whose initial value is not retrieved yet is reported with
+// ErrLatestNotSet.
+func (m *MultiWatcher) Latest() map[string]Latest {
+	snapshot := make(map[string]Latest, len(m.watchers))
+	for path, w := range m.watchers {
+		snapshot[path] = w.Latest()
+	}
+	return snapshot
+}
+
+// AwaitInitialValues awaits until every watched file has its initial value
+// and returns the combined snapshot.
+func (m *MultiWatcher) AwaitInitialValues() map[string]Latest {
+	<-m.readyCh
+	return m.Latest()
+}
+
+// Watch registers a listener which is invoked with the combined snapshot
+// whenever any of the watched files changes. The first invocation happens
+// only after every file has its initial value.
+func (m *MultiWatcher) Watch(listener func(snapshot map[string]Latest)) error {
+	if listener == nil {
+		return fmt.Errorf("centraldogma: listener should not be nil")
+	}
+	m.listenersMutex.Lock()
+	m.listeners = append(m.listeners, listener)
+	m.listenersMutex.Unlock()
+	return nil
+}
+
+func (m *MultiWatcher) notifyListeners() {
+	snapshot := m.Latest()
+	m.listenersMutex.Lock()
+	listeners := make([]func(snapshot map[string]Latest), len(m.listeners))
+	copy(listeners, m.listeners)
+	m.listenersMutex.Unlock()
+	for _, listener := range listeners {
+		listener(snapshot)
+	}
+}
+
+// Close stops watching every file. It is safe to call Close more than once.
+func (m *MultiWatcher) Close() {
+	m.close()
+}
+
+func (m *MultiWatcher) close() {
+	m.closeOnce.Do(func() {
+		close(m.closedCh)
+		for _, w := range m.watchers {
+			w.Close()
+		}
+	})
+}
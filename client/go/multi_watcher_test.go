@@ -0,0 +1,102 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWatchFiles(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("If-None-Match") {
+			case "":
+				fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":1}`)
+			case "1":
+				fmt.Fprint(w, `{"revision":2,`+
+					`"entry":{"path":"/a.json","type":"JSON","content":{"a":2},"revision":2}}`)
+			default:
+				<-r.Context().Done()
+			}
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/b.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == "" {
+				fmt.Fprint(w, `{"path":"/b.json","type":"JSON","content":{"b":1},"revision":1}`)
+				return
+			}
+			<-r.Context().Done()
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := client.WatchFiles(context.Background(), "foo", "bar",
+		[]*Query{{Path: "/a.json", Type: Identity}, {Path: "/b.json", Type: Identity}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	snapshots := make(chan map[string]Latest, 16)
+	if err := m.Watch(func(snapshot map[string]Latest) {
+		snapshots <- snapshot
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case snapshot := <-snapshots:
+			a, b := snapshot["/a.json"], snapshot["/b.json"]
+			// No combined notification may carry a partially initialized
+			// snapshot.
+			if a.Err != nil || b.Err != nil {
+				t.Fatalf("snapshot with uninitialized values: %+v", snapshot)
+			}
+			if b.Revision != 1 {
+				t.Fatalf("revision of /b.json: %v, want 1", b.Revision)
+			}
+			if a.Revision == 2 {
+				return // the change to /a.json reached the combined snapshot
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for the combined snapshot to see revision 2 of /a.json")
+		}
+	}
+}
+
+func TestWatchFilesRejectsDuplicatePath(t *testing.T) {
+	client, err := NewClientWithToken("localhost:36462", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.WatchFiles(context.Background(), "foo", "bar",
+		[]*Query{{Path: "/a.json", Type: Identity}, {Path: "a.json", Type: Identity}})
+	if err == nil {
+		t.Error("WatchFiles accepted the same path twice, want an error")
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type projectService service
+
+// Project represents a project in the Central Dogma server.
+type Project struct {
+	Name      string  `json:"name"`
+	Creator   *Author `json:"creator,omitempty"`
+	URL       string  `json:"url,omitempty"`
+	CreatedAt string  `json:"createdAt,omitempty"`
+	// RemovedAt and RemovedBy are reported only for removed projects, when
+	// the server records them, and help decide what is ready to purge.
+	RemovedAt string  `json:"removedAt,omitempty"`
+	RemovedBy *Author `json:"removedBy,omitempty"`
+}
+
+// Author represents the author of a commit or the creator of a project or
+// repository.
+type Author struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// ParseAuthor parses the specified string into an Author. It accepts the
+// canonical "Name <email>" form and a bare email address, whose local part
+// becomes the name.
+func ParseAuthor(s string) (*Author, error) {
+	s = strings.TrimSpace(s)
+	if open := strings.IndexByte(s, '<'); open != -1 {
+		if !strings.HasSuffix(s, ">") {
+			return nil, fmt.Errorf("centraldogma: invalid author: %q", s)
+		}
+		name := strings.TrimSpace(s[:open])
+		email := strings.TrimSpace(s[open+1 : len(s)-1])
+		if name == "" || email == "" || !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("centraldogma: invalid author: %q", s)
+		}
+		return &Author{Name: name, Email: email}, nil
+	}
+
+	at := strings.IndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 || strings.ContainsAny(s, " >") {
+		return nil, fmt.Errorf("centraldogma: invalid author: %q", s)
+	}
+	return &Author{Name: s[:at], Email: s}, nil
+}
+
+// String returns the canonical "Name <email>" form of the author.
+func (a *Author) String() string {
+	return fmt.Sprintf("%v <%v>", a.Name, a.Email)
+}
+
+// CreatedAtTime parses the creation time of the project. It returns the zero
+// time without an error when the server did not report one.
+func (p *Project) CreatedAtTime() (time.Time, error) {
+	return parseCreatedAt(p.CreatedAt)
+}
+
+// CreatorString returns the canonical "Name <email>" form of the creator of
+// the project, or an empty string when the server did not report one.
+func (p *Project) CreatorString() string {
+	if p.Creator == nil {
+		return ""
+	}
+	return p.Creator.String()
+}
+
+// parseCreatedAt parses a createdAt timestamp as reported by the server. An
+// empty timestamp parses to the zero time without an error.
+func parseCreatedAt(createdAt string) (time.Time, error) {
+	if createdAt == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, createdAt)
+}
+
+// CreateProject creates a project with the specified name.
+func (c *Client) CreateProject(ctx context.Context, name string) (*Project, error) {
+	return c.project.create(ctx, name, nil)
+}
+
+// ProjectCreation carries the optional metadata of a project creation which
+// the legacy client could send: a schema for validating the contents and the
+// plugins to activate. Servers that do not support them ignore the fields.
+type ProjectCreation struct {
+	Schema  json.RawMessage `json:"schema,omitempty"`
+	Plugins []string        `json:"plugins,omitempty"`
+}
+
+// CreateProjectWithOptions creates a project with the specified name and the
+// given creation metadata.
+func (c *Client) CreateProjectWithOptions(ctx context.Context, name string,
+	options *ProjectCreation) (*Project, error) {
+	return c.project.create(ctx, name, options)
+}
+
+// RemoveProject removes the project with the specified name. A removed
+// project can be restored by UnremoveProject.
+func (c *Client) RemoveProject(ctx context.Context, name string) error {
+	return c.project.remove(ctx, name)
+}
+
+// PurgeProject purges the removed project with the specified name so that it
+// cannot be restored anymore.
+func (c *Client) PurgeProject(ctx context.Context, name string) error {
+	return c.project.purge(ctx, name)
+}
+
+// UnremoveProject restores the removed project with the specified name.
+func (c *Client) UnremoveProject(ctx context.Context, name string) (*Project, error) {
+	return c.project.unremove(ctx, name)
+}
+
+// ListProjects returns the list of projects.
+func (c *Client) ListProjects(ctx context.Context) ([]*Project, error) {
+	return c.project.list(ctx)
+}
+
+// ProjectAccess pairs a project with the effective role the current
+// identity has on it, as reported by the server.
+type ProjectAccess struct {
+	Name string `json:"name"`
+	Role string `json:"userRole"`
+}
+
+// AccessibleProjects returns the projects the current identity can access
+// together with its effective role on each, so that multi-tenant tooling can
+// tell an owned project from one it can merely read.
+func (c *Client) AccessibleProjects(ctx context.Context) ([]*ProjectAccess, error) {
+	req, err := c.newRequest(http.MethodGet, defaultPathPrefix+"projects", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []*ProjectAccess
+	if _, err := c.do(ctx, req, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// ListRemovedProjects returns the list of the removed projects which can be
+// restored by UnremoveProject, including their removal metadata when the
+// server provides it.
+func (c *Client) ListRemovedProjects(ctx context.Context) ([]*Project, error) {
+	return c.project.listRemoved(ctx, 0, 0)
+}
+
+// ListRemovedProjectsPaged is like ListRemovedProjects but returns at most
+// limit projects starting at offset, for servers with many removed projects.
+// A zero limit means no limit.
+func (c *Client) ListRemovedProjectsPaged(ctx context.Context, offset, limit int) ([]*Project, error) {
+	return c.project.listRemoved(ctx, offset, limit)
+}
+
+func (p *projectService) create(ctx context.Context, name string, options *ProjectCreation) (*Project, error) {
+	payload := &struct {
+		Name    string          `json:"name"`
+		Schema  json.RawMessage `json:"schema,omitempty"`
+		Plugins []string        `json:"plugins,omitempty"`
+	}{Name: name}
+	if options != nil {
+		payload.Schema = options.Schema
+		payload.Plugins = options.Plugins
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := p.client.newRequest(http.MethodPost, defaultPathPrefix+"projects", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	project := new(Project)
+	if _, err := p.client.do(ctx, req, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (p *projectService) remove(ctx context.Context, name string) error {
+	req, err := p.client.newRequest(http.MethodDelete, defaultPathPrefix+"projects/"+name, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.do(ctx, req, nil)
+	return err
+}
+
+func (p *projectService) purge(ctx context.Context, name string) error {
+	req, err := p.client.newRequest(http.MethodDelete, defaultPathPrefix+"projects/"+name+"/removed", nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.do(ctx, req, nil)
+	return err
+}
+
+func (p *projectService) unremove(ctx context.Context, name string) (*Project, error) {
+	body := []byte(`[{"op":"replace","path":"/status","value":"active"}]`)
+	req, err := p.client.newRequest(http.MethodPatch, defaultPathPrefix+"projects/"+name, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	project := new(Project)
+	if _, err := p.client.do(ctx, req, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// removedListQuery builds the query string of a removed-entities listing
+// with optional pagination.
+func removedListQuery(offset, limit int) string {
+	v := url.Values{}
+	v.Set("status", "removed")
+	if offset > 0 {
+		v.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	return v.Encode()
+}
+
+func (p *projectService) list(ctx context.Context) ([]*Project, error) {
+	req, err := p.client.newRequest(http.MethodGet, defaultPathPrefix+"projects", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []*Project
+	if _, err := p.client.do(ctx, req, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (p *projectService) listRemoved(ctx context.Context, offset, limit int) ([]*Project, error) {
+	req, err := p.client.newRequest(http.MethodGet,
+		defaultPathPrefix+"projects?"+removedListQuery(offset, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []*Project
+	if _, err := p.client.do(ctx, req, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
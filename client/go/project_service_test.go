@@ -0,0 +1,147 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListRemovedProjectsMetadata(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		fmt.Fprint(w, `[{"name":"foo",`+
+			`"removedAt":"2021-06-01T00:00:00Z",`+
+			`"removedBy":{"name":"alice","email":"alice@example.com"}}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := client.ListRemovedProjectsPaged(context.Background(), 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "limit=5&offset=10&status=removed" {
+		t.Errorf("query string: %q", gotQuery)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("projects: %v, want 1", len(projects))
+	}
+	if projects[0].RemovedAt != "2021-06-01T00:00:00Z" {
+		t.Errorf("removedAt: %q", projects[0].RemovedAt)
+	}
+	if projects[0].RemovedBy == nil || projects[0].RemovedBy.Name != "alice" {
+		t.Errorf("removedBy: %+v", projects[0].RemovedBy)
+	}
+}
+
+func TestProjectCreatedAtTime(t *testing.T) {
+	project := &Project{CreatedAt: "2021-06-01T02:03:04Z"}
+	created, err := project.CreatedAtTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2021, 6, 1, 2, 3, 4, 0, time.UTC); !created.Equal(want) {
+		t.Errorf("created: %v, want %v", created, want)
+	}
+
+	created, err = (&Project{}).CreatedAtTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created.IsZero() {
+		t.Errorf("created: %v, want the zero time for an empty createdAt", created)
+	}
+}
+
+func TestProjectCreatorString(t *testing.T) {
+	project := &Project{Creator: &Author{Name: "alice", Email: "alice@example.com"}}
+	if got := project.CreatorString(); got != "alice <alice@example.com>" {
+		t.Errorf("creator: %q", got)
+	}
+	if got := (&Project{}).CreatorString(); got != "" {
+		t.Errorf("creator: %q, want empty for a missing creator", got)
+	}
+}
+
+func TestCreateProjectWithOptions(t *testing.T) {
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"foo"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &ProjectCreation{
+		Schema:  json.RawMessage(`{"type":"object"}`),
+		Plugins: []string{"mirror"},
+	}
+	if _, err := client.CreateProjectWithOptions(context.Background(), "foo", options); err != nil {
+		t.Fatal(err)
+	}
+	if sent["name"] != "foo" {
+		t.Errorf("name sent: %v", sent["name"])
+	}
+	if schema, ok := sent["schema"].(map[string]interface{}); !ok || schema["type"] != "object" {
+		t.Errorf("schema sent: %v", sent["schema"])
+	}
+	if plugins, ok := sent["plugins"].([]interface{}); !ok || len(plugins) != 1 || plugins[0] != "mirror" {
+		t.Errorf("plugins sent: %v", sent["plugins"])
+	}
+}
+
+func TestAccessibleProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"foo","userRole":"OWNER"},{"name":"bar","userRole":"GUEST"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := client.AccessibleProjects(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("projects: %+v, want 2", projects)
+	}
+	if projects[0].Name != "foo" || projects[0].Role != "OWNER" {
+		t.Errorf("first project: %+v, want foo as OWNER", projects[0])
+	}
+	if projects[1].Name != "bar" || projects[1].Role != "GUEST" {
+		t.Errorf("second project: %+v, want bar as GUEST", projects[1])
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProjectSummary aggregates a project and its repositories in one call,
+// giving dashboards a single consistent snapshot instead of stitching
+// several listings together.
+type ProjectSummary struct {
+	Project      *Project      `json:"project"`
+	Repositories []*Repository `json:"repositories"`
+}
+
+// ProjectSummary returns the specified project together with its
+// repositories. The head revision of each repository is re-normalized at
+// call time, so the summary reflects the current heads rather than the
+// point-in-time snapshot a listing alone would give.
+func (c *Client) ProjectSummary(ctx context.Context, projectName string) (*ProjectSummary, error) {
+	projects, err := c.project.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary := new(ProjectSummary)
+	for _, project := range projects {
+		if project.Name == projectName {
+			summary.Project = project
+			break
+		}
+	}
+	if summary.Project == nil {
+		return nil, fmt.Errorf("centraldogma: project %v does not exist", projectName)
+	}
+
+	repos, err := c.repo.list(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		head, err := c.repo.normalizeRevision(ctx, projectName, repo.Name, "-1")
+		if err != nil {
+			return nil, err
+		}
+		repo.HeadRevision = head
+	}
+	summary.Repositories = repos
+	return summary, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProjectSummary(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"foo","creator":{"name":"alice","email":"alice@example.com"}},`+
+			`{"name":"other"}]`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		// The listing reports a stale head which the summary re-normalizes.
+		fmt.Fprint(w, `[{"name":"bar","headRevision":40},{"name":"baz","headRevision":6}]`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"revision":42}`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/baz/revision/-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"revision":7}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := client.ProjectSummary(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Project == nil || summary.Project.Name != "foo" ||
+		summary.Project.CreatorString() != "alice <alice@example.com>" {
+		t.Errorf("project: %+v", summary.Project)
+	}
+	if len(summary.Repositories) != 2 {
+		t.Fatalf("repositories: %+v, want 2", summary.Repositories)
+	}
+	if summary.Repositories[0].Name != "bar" || summary.Repositories[0].HeadRevision != 42 {
+		t.Errorf("first repository: %+v, want the re-normalized head 42", summary.Repositories[0])
+	}
+	if summary.Repositories[1].Name != "baz" || summary.Repositories[1].HeadRevision != 7 {
+		t.Errorf("second repository: %+v, want the re-normalized head 7", summary.Repositories[1])
+	}
+}
+
+func TestProjectSummaryUnknownProject(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"other"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ProjectSummary(context.Background(), "foo"); err == nil {
+		t.Error("ProjectSummary succeeded for a project that does not exist")
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PushDirectory pushes every file under the specified local directory into
+// the repository in a single commit. A file whose name ends with ".json" is
+// pushed as JSON and everything else as text.
+func (c *Client) PushDirectory(ctx context.Context, projectName, repoName, baseRevision string,
+	commitMessage *CommitMessage, localDir string) (*Commit, error) {
+	changes, err := changesFromDirectory(localDir)
+	if err != nil {
+		return nil, err
+	}
+	return c.content.push(ctx, projectName, repoName, baseRevision, commitMessage, changes)
+}
+
+// SyncDirectory pushes the specified local directory into the repository
+// like PushDirectory, but compares every file against the current repository
+// content first and includes only the files that are new or changed, so that
+// re-importing a mostly unchanged directory does not create noisy no-op
+// diffs. When removeMissing is set, repository files that no longer exist
+// locally are removed in the same commit. It returns a nil commit when the
+// repository is already in sync.
+func (c *Client) SyncDirectory(ctx context.Context, projectName, repoName, baseRevision string,
+	commitMessage *CommitMessage, localDir string, removeMissing bool) (*Commit, error) {
+	local, err := changesFromDirectory(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := c.GetFiles(ctx, projectName, repoName, baseRevision, "/**")
+	if err != nil {
+		return nil, err
+	}
+	remoteByPath := make(map[string]*Entry, len(remote))
+	for _, entry := range remote {
+		if entry.Type != Directory {
+			remoteByPath[entry.Path] = entry
+		}
+	}
+
+	var changes []*Change
+	for _, change := range local {
+		entry, exists := remoteByPath[change.Path]
+		if !exists || !contentEqual(change, entry) {
+			changes = append(changes, change)
+		}
+		delete(remoteByPath, change.Path)
+	}
+	if removeMissing {
+		removed := make([]string, 0, len(remoteByPath))
+		for path := range remoteByPath {
+			removed = append(removed, path)
+		}
+		sort.Strings(removed)
+		for _, path := range removed {
+			changes = append(changes, &Change{Path: path, Type: Remove})
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return c.content.push(ctx, projectName, repoName, baseRevision, commitMessage, changes)
+}
+
+// contentEqual reports whether an upsert change would leave the existing
+// entry untouched. JSON is compared structurally, so formatting differences
+// do not count as changes.
+func contentEqual(change *Change, entry *Entry) bool {
+	switch change.Type {
+	case UpsertJSON:
+		if entry.Type != JSON {
+			return false
+		}
+		raw, ok := change.Content.(json.RawMessage)
+		if !ok {
+			return false
+		}
+		var local, remote interface{}
+		if json.Unmarshal(raw, &local) != nil || json.Unmarshal(entry.Content, &remote) != nil {
+			return false
+		}
+		return reflect.DeepEqual(local, remote)
+	case UpsertText:
+		if entry.Type != Text {
+			return false
+		}
+		text, ok := change.Content.(string)
+		return ok && text == string(entry.Content)
+	}
+	return false
+}
+
+// PushLocalFiles pushes the specified local files into the repository in a
+// single commit. The map key is the repository path and the value is the
+// local path of the file to push there. A file whose repository path ends
+// with ".json" is pushed as JSON and everything else as text.
+func (c *Client) PushLocalFiles(ctx context.Context, projectName, repoName, baseRevision string,
+	commitMessage *CommitMessage, files map[string]string) (*Commit, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("centraldogma: no files to push")
+	}
+
+	repoPaths := make([]string, 0, len(files))
+	for repoPath := range files {
+		repoPaths = append(repoPaths, repoPath)
+	}
+	sort.Strings(repoPaths)
+
+	changes := make([]*Change, 0, len(files))
+	for _, repoPath := range repoPaths {
+		change, err := newUpsertChange(files[repoPath], ensureLeadingSlash(repoPath))
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return c.content.push(ctx, projectName, repoName, baseRevision, commitMessage, changes)
+}
+
+// PushDirectoryInBatches pushes every file under the specified local
+// directory as a series of sequential commits of at most batchSize changes
+// each, so that very large imports do not hit server payload limits. The
+// summary of each commit message carries a "(i/n)" suffix, and each batch is
+// based on the head revision produced by the previous one. When progress is
+// non-nil, it is invoked after each batch with the number of files pushed so
+// far and the total.
+func (c *Client) PushDirectoryInBatches(ctx context.Context, projectName, repoName, baseRevision string,
+	commitMessage *CommitMessage, localDir string, batchSize int,
+	progress func(pushed, total int)) ([]*Commit, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("centraldogma: batchSize should be positive: %v", batchSize)
+	}
+	if commitMessage == nil || commitMessage.Summary == "" {
+		return nil, fmt.Errorf("centraldogma: summary of the commit message should not be empty")
+	}
+
+	changes, err := changesFromDirectory(localDir)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(changes)
+	batches := (total + batchSize - 1) / batchSize
+	commits := make([]*Commit, 0, batches)
+	base := baseRevision
+
+	for i := 0; i < batches; i++ {
+		end := (i + 1) * batchSize
+		if end > total {
+			end = total
+		}
+		batch := changes[i*batchSize : end]
+
+		batchMessage := *commitMessage
+		batchMessage.Summary = fmt.Sprintf("%v (%v/%v)", commitMessage.Summary, i+1, batches)
+		commit, err := c.content.push(ctx, projectName, repoName, base, &batchMessage, batch)
+		if err != nil {
+			return commits, err
+		}
+		commits = append(commits, commit)
+		base = strconv.Itoa(commit.Revision)
+		if progress != nil {
+			progress(end, total)
+		}
+	}
+	return commits, nil
+}
+
+// changesFromDirectory builds the upsert changes for every file under the
+// specified local directory, sorted by repository path.
+func changesFromDirectory(localDir string) ([]*Change, error) {
+	var changes []*Change
+	err := filepath.WalkDir(localDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		change, err := newUpsertChange(path, "/"+filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// newUpsertChange builds an upsert change for the specified local file. A
+// file whose repository path ends with ".json" is pushed as JSON and
+// everything else as text.
+func newUpsertChange(localPath, repoPath string) (*Change, error) {
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(strings.ToLower(repoPath), ".json") {
+		if !json.Valid(content) {
+			return nil, fmt.Errorf("centraldogma: %v is not valid JSON", localPath)
+		}
+		return &Change{Path: repoPath, Type: UpsertJSON, Content: json.RawMessage(content)}, nil
+	}
+	return &Change{Path: repoPath, Type: UpsertText, Content: string(content)}, nil
+}
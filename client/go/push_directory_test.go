@@ -0,0 +1,242 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPushDirectoryInBatches(t *testing.T) {
+	localDir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.json": `{"a":1}`,
+		"b.txt":  "b",
+		"c.txt":  "c",
+	} {
+		if err := os.WriteFile(filepath.Join(localDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type pushRequest struct {
+		revision string
+		changes  int
+		summary  string
+	}
+	var pushes []pushRequest
+	revision := 10
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(struct {
+			CommitMessage *CommitMessage `json:"commitMessage"`
+			Changes       []*Change      `json:"changes"`
+		})
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Error(err)
+		}
+		pushes = append(pushes, pushRequest{
+			revision: r.URL.Query().Get("revision"),
+			changes:  len(body.Changes),
+			summary:  body.CommitMessage.Summary,
+		})
+		revision++
+		fmt.Fprintf(w, `{"revision":%v}`, revision)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progressed []int
+	commits, err := client.PushDirectoryInBatches(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "Import configs"}, localDir, 2,
+		func(pushed, total int) { progressed = append(progressed, pushed) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pushes) != 2 {
+		t.Fatalf("pushes: %v, want 2", len(pushes))
+	}
+	if pushes[0].revision != "-1" || pushes[0].changes != 2 ||
+		pushes[0].summary != "Import configs (1/2)" {
+		t.Errorf("first push: %+v", pushes[0])
+	}
+	// The second batch must be based on the head produced by the first.
+	if pushes[1].revision != "11" || pushes[1].changes != 1 ||
+		pushes[1].summary != "Import configs (2/2)" {
+		t.Errorf("second push: %+v", pushes[1])
+	}
+	if len(commits) != 2 || commits[0].Revision != 11 || commits[1].Revision != 12 {
+		t.Errorf("commits: %+v", commits)
+	}
+	if len(progressed) != 2 || progressed[0] != 2 || progressed[1] != 3 {
+		t.Errorf("progress: %v, want [2 3]", progressed)
+	}
+}
+
+func TestPushLocalFiles(t *testing.T) {
+	localDir := t.TempDir()
+	jsonPath := filepath.Join(localDir, "config.json")
+	textPath := filepath.Join(localDir, "notes.txt")
+	if err := os.WriteFile(jsonPath, []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(textPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []*Change
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(struct {
+			CommitMessage *CommitMessage `json:"commitMessage"`
+			Changes       []*Change      `json:"changes"`
+		})
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Error(err)
+		}
+		changes = body.Changes
+		fmt.Fprint(w, `{"revision":2}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.PushLocalFiles(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "Add files"}, map[string]string{
+			"/configs/config.json": jsonPath,
+			"notes.txt":            textPath,
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Revision != 2 {
+		t.Errorf("revision: %v, want 2", commit.Revision)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("changes: %v, want 2", len(changes))
+	}
+	if changes[0].Path != "/configs/config.json" || changes[0].Type != UpsertJSON {
+		t.Errorf("first change: %+v", changes[0])
+	}
+	if content, _ := json.Marshal(changes[0].Content); string(content) != `{"a":1}` {
+		t.Errorf("first content: %v", string(content))
+	}
+	if changes[1].Path != "/notes.txt" || changes[1].Type != UpsertText ||
+		changes[1].Content != "hello" {
+		t.Errorf("second change: %+v", changes[1])
+	}
+}
+
+func TestSyncDirectoryPushesOnlyChangedFiles(t *testing.T) {
+	localDir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.json": `{"a":1}`,
+		"b.txt":  "changed\n",
+	} {
+		if err := os.WriteFile(filepath.Join(localDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var pushed struct {
+		Changes []*Change `json:"changes"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// The repository already holds a.json with equivalent JSON, the
+			// old b.txt and a file that no longer exists locally.
+			fmt.Fprint(w, `[{"path":"/a.json","type":"JSON","content":{ "a" : 1 }},`+
+				`{"path":"/b.txt","type":"TEXT","content":"old\n"},`+
+				`{"path":"/gone.txt","type":"TEXT","content":"bye\n"}]`)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&pushed); err != nil {
+			t.Error(err)
+		}
+		fmt.Fprint(w, `{"revision":9}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.SyncDirectory(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "sync"}, localDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit == nil || commit.Revision != 9 {
+		t.Fatalf("commit: %+v, want revision 9", commit)
+	}
+
+	if len(pushed.Changes) != 2 {
+		t.Fatalf("pushed changes: %+v, want only the changed file and the removal", pushed.Changes)
+	}
+	if pushed.Changes[0].Path != "/b.txt" || pushed.Changes[0].Type != UpsertText {
+		t.Errorf("first change: %+v", pushed.Changes[0])
+	}
+	if pushed.Changes[1].Path != "/gone.txt" || pushed.Changes[1].Type != Remove {
+		t.Errorf("second change: %+v", pushed.Changes[1])
+	}
+}
+
+func TestSyncDirectoryNoChanges(t *testing.T) {
+	localDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(localDir, "a.json"), []byte(`{"a":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pushes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			pushes++
+			return
+		}
+		fmt.Fprint(w, `[{"path":"/a.json","type":"JSON","content":{"a":1}}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.SyncDirectory(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "sync"}, localDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit != nil {
+		t.Errorf("commit: %+v, want nil when nothing changed", commit)
+	}
+	if pushes != 0 {
+		t.Errorf("pushes: %v, want 0", pushes)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QueryBuilder builds a Query fluently, e.g.
+//
+//	query, err := centraldogma.NewQuery("/a.json").
+//		JSONPath("$.hosts").
+//		JSONPath("$[0]").
+//		Build()
+//
+// Without any JSONPath expression, Build returns an identity query.
+type QueryBuilder struct {
+	path        string
+	expressions []string
+}
+
+// NewQuery returns a builder for a query on the file at the specified path.
+func NewQuery(path string) *QueryBuilder {
+	return &QueryBuilder{path: path}
+}
+
+// JSONPath appends a JSON path expression, applied after the ones added
+// before it.
+func (b *QueryBuilder) JSONPath(expression string) *QueryBuilder {
+	b.expressions = append(b.expressions, expression)
+	return b
+}
+
+// Build returns the query. It fails when a JSON path expression is used on
+// a file that is not JSON, which the server would reject anyway.
+func (b *QueryBuilder) Build() (*Query, error) {
+	if b.path == "" {
+		return nil, fmt.Errorf("centraldogma: query has no path")
+	}
+	if len(b.expressions) == 0 {
+		return &Query{Path: b.path, Type: Identity}, nil
+	}
+	if !strings.HasSuffix(strings.ToLower(b.path), ".json") {
+		return nil, fmt.Errorf(
+			"centraldogma: JSON path expressions require a .json file: %v", b.path)
+	}
+	return &Query{Path: b.path, Type: JSONPath, Expressions: b.expressions}, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryBuilderIdentity(t *testing.T) {
+	query, err := NewQuery("/a.txt").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Query{Path: "/a.txt", Type: Identity}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("query: %+v, want %+v", query, want)
+	}
+}
+
+func TestQueryBuilderJSONPath(t *testing.T) {
+	query, err := NewQuery("/a.json").JSONPath("$.hosts").JSONPath("$[0]").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Query{
+		Path:        "/a.json",
+		Type:        JSONPath,
+		Expressions: []string{"$.hosts", "$[0]"},
+	}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("query: %+v, want %+v", query, want)
+	}
+}
+
+func TestQueryBuilderRejectsJSONPathOnText(t *testing.T) {
+	if _, err := NewQuery("/a.txt").JSONPath("$.hosts").Build(); err == nil {
+		t.Error("Build accepted a JSON path on a text file, want an error")
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type repositoryService service
+
+// Repository represents a repository in the Central Dogma server.
+type Repository struct {
+	Name         string  `json:"name"`
+	Creator      *Author `json:"creator,omitempty"`
+	HeadRevision int     `json:"headRevision,omitempty"`
+	URL          string  `json:"url,omitempty"`
+	CreatedAt    string  `json:"createdAt,omitempty"`
+	// Description is the human-readable description of the repository,
+	// reported when one was set via SetRepositoryDescription.
+	Description string `json:"description,omitempty"`
+	// RemovedAt and RemovedBy are reported only for removed repositories,
+	// when the server records them.
+	RemovedAt string  `json:"removedAt,omitempty"`
+	RemovedBy *Author `json:"removedBy,omitempty"`
+}
+
+// CreatedAtTime parses the creation time of the repository. It returns the
+// zero time without an error when the server did not report one.
+func (r *Repository) CreatedAtTime() (time.Time, error) {
+	return parseCreatedAt(r.CreatedAt)
+}
+
+// CreatorString returns the canonical "Name <email>" form of the creator of
+// the repository, or an empty string when the server did not report one.
+func (r *Repository) CreatorString() string {
+	if r.Creator == nil {
+		return ""
+	}
+	return r.Creator.String()
+}
+
+// CreateRepository creates a repository with the specified name under the
+// specified project.
+func (c *Client) CreateRepository(ctx context.Context, projectName, repoName string) (*Repository, error) {
+	return c.repo.create(ctx, projectName, repoName, nil)
+}
+
+// RepositoryCreation carries the optional metadata of a repository creation
+// which the legacy client could send, such as the head revision to start
+// from. Servers that do not support it ignore the field.
+type RepositoryCreation struct {
+	Head string `json:"head,omitempty"`
+}
+
+// CreateRepositoryWithOptions creates a repository with the specified name
+// and the given creation metadata.
+func (c *Client) CreateRepositoryWithOptions(ctx context.Context, projectName, repoName string,
+	options *RepositoryCreation) (*Repository, error) {
+	return c.repo.create(ctx, projectName, repoName, options)
+}
+
+// CreateRepositoryWithFiles creates a repository and pushes the specified
+// initial files into it as one logical operation, so that a freshly created
+// repository is never observed without its seed files. initialFiles maps each
+// repository path to the change that creates it; a change whose Path is empty
+// takes the map key as its path. When the push fails after the repository was
+// created, the repository is removed again and the push failure is returned;
+// if even the removal fails, both errors are reported.
+func (c *Client) CreateRepositoryWithFiles(ctx context.Context, projectName, repoName string,
+	initialFiles map[string]*Change, commitMessage *CommitMessage) (*Repository, *Commit, error) {
+	if len(initialFiles) == 0 {
+		return nil, nil, fmt.Errorf("centraldogma: no initial files for %v/%v", projectName, repoName)
+	}
+	paths := make([]string, 0, len(initialFiles))
+	for path := range initialFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	changes := make([]*Change, 0, len(paths))
+	for _, path := range paths {
+		change := initialFiles[path]
+		if change.Path == "" {
+			copied := *change
+			copied.Path = path
+			change = &copied
+		}
+		changes = append(changes, change)
+	}
+
+	repo, err := c.repo.create(ctx, projectName, repoName, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	commit, err := c.content.push(ctx, projectName, repoName, "", commitMessage, changes)
+	if err != nil {
+		if removeErr := c.repo.remove(ctx, projectName, repoName); removeErr != nil {
+			return nil, nil, fmt.Errorf(
+				"centraldogma: pushing initial files failed: %v (and rolling back %v/%v failed: %v)",
+				err, projectName, repoName, removeErr)
+		}
+		return nil, nil, err
+	}
+	return repo, commit, nil
+}
+
+// RemoveRepository removes the specified repository. A removed repository
+// can be restored by UnremoveRepository.
+func (c *Client) RemoveRepository(ctx context.Context, projectName, repoName string) error {
+	return c.repo.remove(ctx, projectName, repoName)
+}
+
+// PurgeRepository purges the removed repository so that it cannot be
+// restored anymore.
+func (c *Client) PurgeRepository(ctx context.Context, projectName, repoName string) error {
+	return c.repo.purge(ctx, projectName, repoName)
+}
+
+// UnremoveRepository restores the removed repository.
+func (c *Client) UnremoveRepository(ctx context.Context, projectName, repoName string) (*Repository, error) {
+	return c.repo.unremove(ctx, projectName, repoName)
+}
+
+// ListRepositories returns the list of repositories under the specified
+// project.
+func (c *Client) ListRepositories(ctx context.Context, projectName string) ([]*Repository, error) {
+	return c.repo.list(ctx, projectName)
+}
+
+// ListRemovedRepositories returns the list of the removed repositories under
+// the specified project, including their removal metadata when the server
+// provides it.
+func (c *Client) ListRemovedRepositories(ctx context.Context, projectName string) ([]*Repository, error) {
+	return c.repo.listRemoved(ctx, projectName, 0, 0)
+}
+
+// ListRemovedRepositoriesPaged is like ListRemovedRepositories but returns
+// at most limit repositories starting at offset. A zero limit means no
+// limit.
+func (c *Client) ListRemovedRepositoriesPaged(ctx context.Context, projectName string, offset, limit int) ([]*Repository, error) {
+	return c.repo.listRemoved(ctx, projectName, offset, limit)
+}
+
+// NormalizeRevision converts the specified relative revision such as "-1"
+// into its absolute revision.
+func (c *Client) NormalizeRevision(ctx context.Context, projectName, repoName, revision string) (int, error) {
+	return c.repo.normalizeRevision(ctx, projectName, repoName, revision)
+}
+
+// Pin resolves the current head of the repository to its absolute revision,
+// so that a multi-step process can operate against a fixed revision instead
+// of a head that moves as others push. It is sugar over NormalizeRevision
+// with the intent spelled out.
+func (c *Client) Pin(ctx context.Context, projectName, repoName string) (int, error) {
+	return c.repo.normalizeRevision(ctx, projectName, repoName, "-1")
+}
+
+// ResolveRevision resolves a symbolic revision such as "-1" or "-5" to the
+// commit it denotes, returning the absolute revision together with the
+// commit's author and message. It is NormalizeRevision for callers who want
+// to know what the revision is, not just which number it has.
+func (c *Client) ResolveRevision(ctx context.Context, projectName, repoName, revision string) (*Commit, error) {
+	normalized, err := c.repo.normalizeRevision(ctx, projectName, repoName, revision)
+	if err != nil {
+		return nil, err
+	}
+	rev := strconv.Itoa(normalized)
+	commits, err := c.content.getHistory(ctx, projectName, repoName, rev, rev, "/**", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		// Revision 1 of a repository has no commit to report.
+		return &Commit{Revision: normalized}, nil
+	}
+	return commits[0], nil
+}
+
+// SetRepositoryDescription sets the human-readable description of the
+// repository, so that teams can document what each repository holds. The
+// description is reported back in the Repository struct by listings.
+func (c *Client) SetRepositoryDescription(ctx context.Context, projectName, repoName,
+	description string) error {
+	return c.repo.setDescription(ctx, projectName, repoName, description)
+}
+
+func reposURL(projectName string) string {
+	return fmt.Sprintf("%vprojects/%v/repos", defaultPathPrefix, projectName)
+}
+
+func (r *repositoryService) create(ctx context.Context, projectName, repoName string,
+	options *RepositoryCreation) (*Repository, error) {
+	payload := &struct {
+		Name string `json:"name"`
+		Head string `json:"head,omitempty"`
+	}{Name: repoName}
+	if options != nil {
+		payload.Head = options.Head
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := r.client.newRequest(http.MethodPost, reposURL(projectName), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	repo := new(Repository)
+	if _, err := r.client.do(ctx, req, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *repositoryService) remove(ctx context.Context, projectName, repoName string) error {
+	req, err := r.client.newRequest(http.MethodDelete, reposURL(projectName)+"/"+repoName, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.do(ctx, req, nil)
+	return err
+}
+
+func (r *repositoryService) purge(ctx context.Context, projectName, repoName string) error {
+	req, err := r.client.newRequest(http.MethodDelete, reposURL(projectName)+"/"+repoName+"/removed", nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.do(ctx, req, nil)
+	return err
+}
+
+func (r *repositoryService) unremove(ctx context.Context, projectName, repoName string) (*Repository, error) {
+	body := []byte(`[{"op":"replace","path":"/status","value":"active"}]`)
+	req, err := r.client.newRequest(http.MethodPatch, reposURL(projectName)+"/"+repoName, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	repo := new(Repository)
+	if _, err := r.client.do(ctx, req, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *repositoryService) setDescription(ctx context.Context, projectName, repoName,
+	description string) error {
+	patch, err := json.Marshal([]map[string]string{
+		{"op": "replace", "path": "/description", "value": description},
+	})
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%vmetadata/%v/repos/%v", defaultPathPrefix, projectName, repoName)
+	req, err := r.client.newRequest(http.MethodPatch, u, bytes.NewReader(patch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	_, err = r.client.do(ctx, req, nil)
+	return err
+}
+
+func (r *repositoryService) list(ctx context.Context, projectName string) ([]*Repository, error) {
+	req, err := r.client.newRequest(http.MethodGet, reposURL(projectName), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []*Repository
+	if _, err := r.client.do(ctx, req, &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (r *repositoryService) listRemoved(ctx context.Context, projectName string, offset, limit int) ([]*Repository, error) {
+	req, err := r.client.newRequest(http.MethodGet,
+		reposURL(projectName)+"?"+removedListQuery(offset, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []*Repository
+	if _, err := r.client.do(ctx, req, &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (r *repositoryService) normalizeRevision(ctx context.Context, projectName, repoName, revision string) (int, error) {
+	u := fmt.Sprintf("%v/%v/revision/%v", reposURL(projectName), repoName, revision)
+	req, err := r.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	normalized := new(struct {
+		Revision int `json:"revision"`
+	})
+	if _, err := r.client.do(ctx, req, normalized); err != nil {
+		return -1, err
+	}
+	return normalized.Revision, nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateRepositoryWithFiles(t *testing.T) {
+	var created, pushed, removed bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		created = true
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"settings"}`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/settings/contents", func(w http.ResponseWriter, r *http.Request) {
+		pushed = true
+		body := new(struct {
+			Changes []*Change `json:"changes"`
+		})
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Error(err)
+		}
+		if len(body.Changes) != 2 ||
+			body.Changes[0].Path != "/a.json" || body.Changes[1].Path != "/b.txt" {
+			t.Errorf("changes: %+v, want /a.json and /b.txt", body.Changes)
+		}
+		fmt.Fprint(w, `{"revision":2}`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/settings", func(w http.ResponseWriter, r *http.Request) {
+		removed = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, commit, err := client.CreateRepositoryWithFiles(context.Background(), "foo", "settings",
+		map[string]*Change{
+			"/a.json": {Type: UpsertJSON, Content: json.RawMessage(`{"a":1}`)},
+			"/b.txt":  {Type: UpsertText, Content: "b\n"},
+		}, &CommitMessage{Summary: "Seed settings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || !pushed {
+		t.Errorf("created: %v, pushed: %v, want both", created, pushed)
+	}
+	if removed {
+		t.Error("repository was removed although the push succeeded")
+	}
+	if repo.Name != "settings" || commit.Revision != 2 {
+		t.Errorf("repo: %+v, commit: %+v", repo, commit)
+	}
+}
+
+func TestCreateRepositoryWithFilesRollsBackOnPushFailure(t *testing.T) {
+	var removed bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"settings"}`)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/settings/contents", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/api/v1/projects/foo/repos/settings", func(w http.ResponseWriter, r *http.Request) {
+		removed = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = client.CreateRepositoryWithFiles(context.Background(), "foo", "settings",
+		map[string]*Change{"/a.txt": {Type: UpsertText, Content: "a"}},
+		&CommitMessage{Summary: "Seed settings"})
+	if err == nil {
+		t.Fatal("CreateRepositoryWithFiles succeeded although the push failed")
+	}
+	if !removed {
+		t.Error("repository was not removed after the push failed")
+	}
+}
+
+func TestPinReturnsAbsoluteHead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/foo/repos/bar/revision/-1" {
+			t.Errorf("path: %v", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"revision":42}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revision, err := client.Pin(context.Background(), "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != 42 {
+		t.Errorf("revision: %v, want 42", revision)
+	}
+}
+
+func TestCreateRepositoryWithOptions(t *testing.T) {
+	var sent map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"bar"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := &RepositoryCreation{Head: "3"}
+	if _, err := client.CreateRepositoryWithOptions(context.Background(), "foo", "bar", options); err != nil {
+		t.Fatal(err)
+	}
+	if sent["name"] != "bar" || sent["head"] != "3" {
+		t.Errorf("payload sent: %v, want name bar and head 3", sent)
+	}
+}
+
+func TestSetRepositoryDescription(t *testing.T) {
+	var gotPath, gotContentType string
+	var gotPatch []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&gotPatch); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.SetRepositoryDescription(context.Background(), "foo", "bar",
+		"service configs"); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/api/v1/metadata/foo/repos/bar" {
+		t.Errorf("path: %q", gotPath)
+	}
+	if gotContentType != "application/json-patch+json" {
+		t.Errorf("content type: %q", gotContentType)
+	}
+	if len(gotPatch) != 1 || gotPatch[0]["op"] != "replace" ||
+		gotPatch[0]["path"] != "/description" || gotPatch[0]["value"] != "service configs" {
+		t.Errorf("patch: %+v", gotPatch)
+	}
+}
+
+func TestListRepositoriesReportsDescription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bar","headRevision":3,"description":"service configs"}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := client.ListRepositories(context.Background(), "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].Description != "service configs" {
+		t.Errorf("repos: %+v, want the description reported", repos)
+	}
+}
+
+func TestResolveRevision(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":42}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/commits/42",
+		func(w http.ResponseWriter, r *http.Request) {
+			if got := r.URL.Query().Get("to"); got != "42" {
+				t.Errorf("to: %v, want 42", got)
+			}
+			fmt.Fprint(w, `[{"revision":42,"author":{"name":"minux"},`+
+				`"commitMessage":{"summary":"Add a.json"}}]`)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := client.ResolveRevision(context.Background(), "foo", "bar", "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Revision != 42 {
+		t.Errorf("revision: %v, want 42", commit.Revision)
+	}
+	if commit.Author == nil || commit.Author.Name != "minux" {
+		t.Errorf("author: %+v, want minux", commit.Author)
+	}
+	if commit.CommitMessage == nil || commit.CommitMessage.Summary != "Add a.json" {
+		t.Errorf("commit message: %+v, want Add a.json", commit.CommitMessage)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// retryConfig holds the per-request retry limit and the client-wide budget.
+type retryConfig struct {
+	maxRetries int
+	budget     *retryBudget
+}
+
+// retryBudget is a token bucket shared by all requests of a client. Every
+// retry takes one token; tokens refill over time up to the capacity. When the
+// bucket is empty, requests fail fast instead of retrying, which keeps the
+// aggregate retry load on a struggling server bounded.
+type retryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+func newRetryBudget(capacity int, refillInterval time.Duration) *retryBudget {
+	return &retryBudget{
+		tokens:     float64(capacity),
+		capacity:   float64(capacity),
+		refillRate: float64(time.Second) / float64(refillInterval),
+		last:       time.Now(),
+	}
+}
+
+// take refills the bucket for the elapsed time and takes one token,
+// reporting whether one was available.
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.refillRate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// WithRetries makes the client retry a request up to maxRetries times when it
+// fails with a connection error or a 5xx response. Retries across all
+// requests of the client draw from a shared budget of the specified capacity
+// which refills one token per refillInterval, so an outage cannot turn into
+// a retry storm: once the budget is exhausted, requests fail fast until it
+// refills.
+func WithRetries(maxRetries, budget int, refillInterval time.Duration) ClientOption {
+	return func(config *clientConfig) {
+		config.retry = &retryConfig{
+			maxRetries: maxRetries,
+			budget:     newRetryBudget(budget, refillInterval),
+		}
+	}
+}
+
+// retryable reports whether a request outcome is worth retrying: transport
+// failures and server errors are, client errors and context cancellation are
+// not.
+func retryable(code int, err error) bool {
+	if _, ok := err.(*ConnectionError); ok {
+		return true
+	}
+	return code >= 500
+}
+
+// retriedRequest clones the request for another attempt, replaying the body
+// when it can be rewound. It returns nil when the body cannot be replayed.
+func retriedRequest(req *http.Request) *http.Request {
+	if req.Body == nil {
+		return req.Clone(req.Context())
+	}
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	retried := req.Clone(req.Context())
+	retried.Body = body
+	return retried
+}
+
+// doWithRetries sends the request, retrying transient failures within the
+// per-request limit as long as the client-wide budget allows.
+func (c *Client) doWithRetries(ctx context.Context, req *http.Request, resContent interface{}) (int, error) {
+	code, err := c.doRequest(ctx, req, resContent)
+	if c.retry == nil {
+		return code, err
+	}
+	for attempt := 0; attempt < c.retry.maxRetries && retryable(code, err); attempt++ {
+		retried := retriedRequest(req)
+		if retried == nil || !c.retry.budget.take() {
+			break
+		}
+		code, err = c.doRequest(ctx, retried, resContent)
+	}
+	return code, err
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryBudgetExhaustion(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "",
+		WithRetries(1, 2, 200*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Two failing calls retry once each and drain the budget.
+	for i := 0; i < 2; i++ {
+		if _, err := client.ListProjects(ctx); err == nil {
+			t.Fatal("ListProjects succeeded, want a 500")
+		}
+	}
+	if requests != 4 {
+		t.Fatalf("requests: %v, want 2 calls with one retry each", requests)
+	}
+
+	// The budget is empty: the next failing call must not retry.
+	if _, err := client.ListProjects(ctx); err == nil {
+		t.Fatal("ListProjects succeeded, want a 500")
+	}
+	if requests != 5 {
+		t.Fatalf("requests: %v, want the call to fail fast without a retry", requests)
+	}
+
+	// After a refill interval one token is back, so one retry is allowed.
+	time.Sleep(250 * time.Millisecond)
+	if _, err := client.ListProjects(ctx); err == nil {
+		t.Fatal("ListProjects succeeded, want a 500")
+	}
+	if requests != 7 {
+		t.Fatalf("requests: %v, want the retry to resume after refill", requests)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// safeReplaceOp is one operation of a safeReplace JSON patch.
+type safeReplaceOp struct {
+	Op       string      `json:"op"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"oldValue"`
+	Value    interface{} `json:"value"`
+}
+
+// NewSafeReplace returns a change that replaces the JSON value at the
+// specified JSON pointer of the file only when it still equals oldValue, the
+// server-side "safeReplace" patch operation. It gives optimistic concurrency
+// at the field level: two writers updating different fields of the same file
+// no longer clobber each other, and a lost race surfaces as a conflict —
+// check for it with IsChangeConflict — instead of a silent overwrite.
+func NewSafeReplace(path, jsonPointer string, oldValue, newValue interface{}) *Change {
+	return &Change{
+		Path: path,
+		Type: ApplyJSONPatch,
+		Content: []interface{}{
+			safeReplaceOp{Op: "safeReplace", Path: jsonPointer, OldValue: oldValue, Value: newValue},
+		},
+	}
+}
+
+// IsChangeConflict reports whether the error from Push means the server
+// rejected the commit because it conflicts with the current state of the
+// repository, e.g. the oldValue of a safeReplace no longer matched. The
+// caller typically re-reads the file and retries.
+func IsChangeConflict(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusConflict ||
+		strings.Contains(apiErr.Exception, "ChangeConflictException")
+}
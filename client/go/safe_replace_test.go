@@ -0,0 +1,68 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewSafeReplace(t *testing.T) {
+	change := NewSafeReplace("/a.json", "/version", "1.0.0", "1.0.1")
+
+	if change.Path != "/a.json" || change.Type != ApplyJSONPatch {
+		t.Errorf("change: %+v, want an APPLY_JSON_PATCH of /a.json", change)
+	}
+	b, err := json.Marshal(change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"path":"/a.json","type":"APPLY_JSON_PATCH",` +
+		`"content":[{"op":"safeReplace","path":"/version","oldValue":"1.0.0","value":"1.0.1"}]}`
+	if string(b) != want {
+		t.Errorf("marshaled change: %v, want %v", string(b), want)
+	}
+}
+
+func TestIsChangeConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.ChangeConflictException",`+
+			`"message":"/a.json (oldValue mismatch)"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change := NewSafeReplace("/a.json", "/version", "1.0.0", "1.0.1")
+	_, err = client.Push(context.Background(), "foo", "bar", "-1",
+		&CommitMessage{Summary: "Bump version"}, []*Change{change})
+	if err == nil {
+		t.Fatal("Push succeeded, want a conflict")
+	}
+	if !IsChangeConflict(err) {
+		t.Errorf("IsChangeConflict(%v) = false, want true", err)
+	}
+	if IsChangeConflict(fmt.Errorf("plumbing broke")) {
+		t.Error("IsChangeConflict reported a non-API error as a conflict")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The login performed during client construction retries transient failures
+// up to loginAttempts times, doubling the delay between attempts, so that a
+// brief auth-server hiccup does not fail client creation.
+const (
+	loginAttempts     = 3
+	loginInitialDelay = 100 * time.Millisecond
+)
+
+// SecurityEnabled returns whether the security of the server is enabled,
+// i.e. whether the server requires a login.
+func (c *Client) SecurityEnabled() (bool, error) {
+	return c.SecurityEnabledContext(context.Background())
+}
+
+// SecurityEnabledContext returns whether the security of the server is
+// enabled, aborting the probe when the specified context is done.
+func (c *Client) SecurityEnabledContext(ctx context.Context) (bool, error) {
+	req, err := c.newRequest(http.MethodGet, "security_enabled", nil)
+	if err != nil {
+		return false, err
+	}
+
+	code, err := c.do(ctx, req, nil)
+	if code == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// login logs into the server with the specified username and password and
+// returns the session token. Transport failures and 5xx responses are
+// retried with a bounded backoff; authentication failures are not.
+func (c *Client) login(ctx context.Context, username, password string) (string, error) {
+	delay := loginInitialDelay
+	for attempt := 0; ; attempt++ {
+		token, code, err := c.loginOnce(ctx, username, password)
+		if err == nil || attempt == loginAttempts-1 || !retryable(code, err) {
+			return token, err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// loginOnce performs a single login request.
+func (c *Client) loginOnce(ctx context.Context, username, password string) (string, int, error) {
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("password", password)
+	form.Set("remember_me", strconv.FormatBool(!c.ephemeralSession))
+
+	req, err := c.newRequest(http.MethodPost, defaultPathPrefix+"login",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	token := new(struct {
+		AccessToken string `json:"access_token"`
+	})
+	code, err := c.do(ctx, req, token)
+	if err != nil {
+		return "", code, err
+	}
+	return token.AccessToken, code, nil
+}
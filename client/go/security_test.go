@@ -0,0 +1,108 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoginSessionPersistence(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{name: "durable by default", want: "true"},
+		{name: "ephemeral on request", opts: []ClientOption{WithEphemeralSession()}, want: "false"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rememberMe string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "login") {
+					r.ParseForm()
+					rememberMe = r.PostForm.Get("remember_me")
+					fmt.Fprint(w, `{"access_token":"session-token"}`)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "user", "pass", test.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rememberMe != test.want {
+				t.Errorf("remember_me: %q, want %q", rememberMe, test.want)
+			}
+			if client.token != "session-token" {
+				t.Errorf("token: %q", client.token)
+			}
+		})
+	}
+}
+
+func TestLoginRetriesTransientFailure(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "login") {
+			logins++
+			if logins == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"session-token"}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "user", "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logins != 2 {
+		t.Errorf("logins: %v, want a retry after the transient failure", logins)
+	}
+	if client.token != "session-token" {
+		t.Errorf("token: %q", client.token)
+	}
+}
+
+func TestLoginDoesNotRetryAuthFailure(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "login") {
+			logins++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, "user", "wrong"); err == nil {
+		t.Error("NewClient succeeded with rejected credentials")
+	}
+	if logins != 1 {
+		t.Errorf("logins: %v, want no retry of an authentication failure", logins)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TypedWatcher is a Watcher whose values are decoded into T, so that
+// listeners receive their configuration type instead of interface{}.
+type TypedWatcher[T any] struct {
+	watcher *Watcher
+}
+
+// FileWatcherOf returns a started TypedWatcher which watches the JSON file
+// matched by the given query and decodes its content into T with
+// encoding/json.
+func FileWatcherOf[T any](c *Client, projectName, repoName string, query *Query) (*TypedWatcher[T], error) {
+	if query == nil {
+		return nil, fmt.Errorf("centraldogma: query should not be nil")
+	}
+	w := newFileWatcher(c, projectName, repoName, query, DefaultWatchTimeout)
+	w.initialValueFunc = func(ctx context.Context) (*Latest, error) {
+		entry, err := c.content.getFile(ctx, projectName, repoName, "-1", query)
+		if err != nil {
+			return nil, err
+		}
+		value, err := typedWatchValue[T](entry)
+		if err != nil {
+			return nil, err
+		}
+		return &Latest{Revision: entry.Revision, Value: value}, nil
+	}
+	w.convertingResultFunc = func(result *WatchResult) *Latest {
+		value, err := typedWatchValue[T](result.Entry)
+		return &Latest{Revision: result.Revision, Value: value, Err: err}
+	}
+	w.start()
+	return &TypedWatcher[T]{watcher: w}, nil
+}
+
+// typedWatchValue decodes the content of the specified entry into T.
+func typedWatchValue[T any](entry *Entry) (T, error) {
+	var value T
+	if entry == nil {
+		return value, fmt.Errorf("centraldogma: watch result has no entry")
+	}
+	if err := json.Unmarshal(entry.Content, &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// Watch registers a listener which is invoked with the revision and the
+// decoded value whenever the watched file is changed.
+func (w *TypedWatcher[T]) Watch(listener func(revision int, value T)) error {
+	if listener == nil {
+		return fmt.Errorf("centraldogma: listener should not be nil")
+	}
+	return w.watcher.Watch(func(revision int, value interface{}) {
+		typed, ok := value.(T)
+		if !ok {
+			return
+		}
+		listener(revision, typed)
+	})
+}
+
+// Latest returns the latest decoded value of the watched file.
+func (w *TypedWatcher[T]) Latest() (T, error) {
+	latest := w.watcher.Latest()
+	if latest.Err != nil {
+		var zero T
+		return zero, latest.Err
+	}
+	value, ok := latest.Value.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("centraldogma: latest value is %T, not the watched type", latest.Value)
+	}
+	return value, nil
+}
+
+// AwaitInitialValueWith awaits up to the specified timeout until the initial
+// value is retrieved and returns it decoded.
+func (w *TypedWatcher[T]) AwaitInitialValueWith(timeout time.Duration) (T, error) {
+	latest := w.watcher.AwaitInitialValueWith(timeout)
+	if latest.Err != nil {
+		var zero T
+		return zero, latest.Err
+	}
+	value, ok := latest.Value.(T)
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("centraldogma: initial value is %T, not the watched type", latest.Value)
+	}
+	return value, nil
+}
+
+// Close stops watching.
+func (w *TypedWatcher[T]) Close() {
+	w.watcher.Close()
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFileWatcherOf(t *testing.T) {
+	type serverConfig struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("If-None-Match") {
+		case "":
+			fmt.Fprint(w, `{"path":"/config.json","type":"JSON",`+
+				`"content":{"host":"a.example.com","port":8080},"revision":3}`)
+		case "3":
+			fmt.Fprint(w, `{"revision":4,"entry":{"path":"/config.json","type":"JSON",`+
+				`"content":{"host":"b.example.com","port":9090},"revision":4}}`)
+		default:
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := FileWatcherOf[serverConfig](client, "foo", "bar",
+		&Query{Path: "/config.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	configs := make(chan serverConfig, 8)
+	if err := watcher.Watch(func(revision int, config serverConfig) {
+		configs <- config
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	initial, err := watcher.AwaitInitialValueWith(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (serverConfig{Host: "a.example.com", Port: 8080}); initial != want {
+		t.Errorf("initial value: %+v, want %+v", initial, want)
+	}
+
+	want := serverConfig{Host: "b.example.com", Port: 9090}
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case config := <-configs:
+			if config == want {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("listener did not receive %+v", want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type watchService service
+
+// DefaultWatchTimeout is the watch timeout which is used when a zero timeout
+// is given.
+const DefaultWatchTimeout = 1 * time.Minute
+
+// ErrWatchTimeout is returned by WatchFile and WatchRepository when the wait
+// window elapsed without a change. It is an expected outcome of a long poll,
+// not a failure: the caller should simply poll again with the same revision.
+var ErrWatchTimeout = errors.New("centraldogma: watch timed out without a change")
+
+// WatchResult is the result of a watch long-poll, delivered when the watched
+// file or repository is changed.
+type WatchResult struct {
+	Revision int    `json:"revision"`
+	Entry    *Entry `json:"entry,omitempty"`
+	// ChangedPaths lists the paths of the entries which were changed since
+	// the last known revision, when the server reports them for a
+	// repository watch.
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+}
+
+// WatchFile awaits up to the specified timeout until the file matched by the
+// given query is changed since the specified last known revision. It returns
+// ErrWatchTimeout when the timeout elapses without a change. Server-side
+// failures are returned as an *APIError carrying the status code and the
+// server message, so a missing repository is distinguishable from other
+// failures with errors.As.
+func (c *Client) WatchFile(ctx context.Context, projectName, repoName string, query *Query,
+	lastKnownRevision int, timeout time.Duration) (*WatchResult, error) {
+	return c.watch.watchFile(ctx, projectName, repoName, query, lastKnownRevision, timeout)
+}
+
+// WatchRepository awaits up to the specified timeout until the files matched
+// by the given path pattern are changed since the specified last known
+// revision. Multiple patterns can be joined with a comma, e.g.
+// "/configs/**,/secrets/**", so that one watch covers disjoint subtrees.
+func (c *Client) WatchRepository(ctx context.Context, projectName, repoName, pathPattern string,
+	lastKnownRevision int, timeout time.Duration) (*WatchResult, error) {
+	return c.watch.watchRepo(ctx, projectName, repoName, pathPattern, lastKnownRevision, timeout)
+}
+
+// normalizePathPattern normalizes each comma-separated segment of the given
+// path pattern, trimming whitespace and prepending a slash where missing.
+func normalizePathPattern(pathPattern string) string {
+	segments := strings.Split(pathPattern, ",")
+	for i, segment := range segments {
+		segments[i] = ensureLeadingSlash(strings.TrimSpace(segment))
+	}
+	return strings.Join(segments, ",")
+}
+
+func (ws *watchService) watchFile(ctx context.Context, projectName, repoName string, query *Query,
+	lastKnownRevision int, timeout time.Duration) (*WatchResult, error) {
+	if query == nil {
+		return nil, fmt.Errorf("centraldogma: query should not be nil")
+	}
+
+	v := url.Values{}
+	if query.Type == JSONPath {
+		for _, expr := range query.Expressions {
+			v.Add("jsonpath", expr)
+		}
+	}
+	u := contentsURL(projectName, repoName, ensureLeadingSlash(query.Path), v)
+	return ws.watchRequest(ctx, u, lastKnownRevision, timeout)
+}
+
+func (ws *watchService) watchRepo(ctx context.Context, projectName, repoName, pathPattern string,
+	lastKnownRevision int, timeout time.Duration) (*WatchResult, error) {
+	u := contentsURL(projectName, repoName, normalizePathPattern(pathPattern), nil)
+	return ws.watchRequest(ctx, u, lastKnownRevision, timeout)
+}
+
+func (ws *watchService) watchRequest(ctx context.Context, u string,
+	lastKnownRevision int, timeout time.Duration) (*WatchResult, error) {
+	if timeout <= 0 {
+		timeout = DefaultWatchTimeout
+	}
+
+	req, err := ws.client.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("If-None-Match", strconv.Itoa(lastKnownRevision))
+	req.Header.Set("Prefer", fmt.Sprintf("wait=%v", int(timeout.Seconds())))
+
+	// Allow the server slack to respond after the wait window before the
+	// client-side deadline kicks in.
+	ctx, cancel := context.WithTimeout(ctx, timeout+10*time.Second)
+	defer cancel()
+
+	result := new(WatchResult)
+	if _, err := ws.client.do(ctx, req, result); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+			return nil, ErrWatchTimeout
+		}
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,608 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchRepositoryMultiplePatterns(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `{"revision":3}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.WatchRepository(context.Background(), "foo", "bar",
+		"/configs/**, secrets/**", 1, 1*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Revision != 3 {
+		t.Errorf("revision: %v, want 3", result.Revision)
+	}
+	if want := "/api/v1/projects/foo/repos/bar/contents/configs/**,/secrets/**"; gotPath != want {
+		t.Errorf("request path: %v, want %v", gotPath, want)
+	}
+}
+
+func TestRepoWatcherMultiplePatterns(t *testing.T) {
+	var mutex sync.Mutex
+	var watchPath string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":1}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/",
+		func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			watchPath = r.URL.Path
+			mutex.Unlock()
+			switch r.Header.Get("If-None-Match") {
+			case "1":
+				// A file under the second subtree was changed.
+				fmt.Fprint(w, `{"revision":2}`)
+			default:
+				// No further changes within the wait window.
+				w.WriteHeader(http.StatusNotModified)
+			}
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.RepoWatcher("foo", "bar", "/configs/**,secrets/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	revisions := make(chan int, 8)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		revisions <- revision
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitRevision(t, revisions, 1)
+	awaitRevision(t, revisions, 2)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if want := "/api/v1/projects/foo/repos/bar/contents/configs/**,/secrets/**"; watchPath != want {
+		t.Errorf("watch path: %v, want %v", watchPath, want)
+	}
+}
+
+func awaitRevision(t *testing.T, revisions <-chan int, want int) {
+	t.Helper()
+	select {
+	case got := <-revisions:
+		if got != want {
+			t.Fatalf("revision: %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for revision %v", want)
+	}
+}
+func TestSetLogOutputCapturesWatchRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logs := &syncBuffer{}
+	SetLogOutput(logs)
+	defer SetLogOutput(io.Discard)
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.RepoWatcher("foo", "bar", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), "failed to get the initial value") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no retry log line captured; logs:\n%v", logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "/api/v1/projects/foo/repos/bar/revision/-1") {
+		t.Errorf("request URL not logged; logs:\n%v", logs.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for use as a log sink written to from
+// the watcher goroutine.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+func TestRepoWatcherSingleJSONFileGetsFileSemantics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("If-None-Match") {
+			case "":
+				fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"k":"v"},"revision":2}`)
+			default:
+				w.WriteHeader(http.StatusNotModified)
+			}
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.RepoWatcher("foo", "bar", "/a.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	latest := watcher.AwaitInitialValueWith(5 * time.Second)
+	if latest.Err != nil {
+		t.Fatal(latest.Err)
+	}
+	value, ok := latest.Value.(map[string]interface{})
+	if !ok {
+		t.Fatalf("value: %T (%v), want the decoded file content", latest.Value, latest.Value)
+	}
+	if value["k"] != "v" {
+		t.Errorf("value: %v", value)
+	}
+}
+
+func TestWatcherClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"revision":1}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.RepoWatcher("foo", "bar", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-watcher.Closed():
+		t.Fatal("Closed channel closed before Close")
+	default:
+	}
+
+	watcher.Close()
+	watcher.Close() // a second Close must not panic
+
+	select {
+	case <-watcher.Closed():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Closed channel not closed after Close")
+	}
+}
+
+func TestRepoWatcherDeliversChangedPaths(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":1}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("If-None-Match") {
+			case "1":
+				fmt.Fprint(w, `{"revision":2,"changedPaths":["/configs/a.json"]}`)
+			default:
+				w.WriteHeader(http.StatusNotModified)
+			}
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.RepoWatcher("foo", "bar", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	updates := make(chan RepoUpdate, 8)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		updates <- value.(RepoUpdate)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case update := <-updates:
+			if update.Revision == 1 {
+				continue // the initial value carries no paths
+			}
+			if update.Revision != 2 || len(update.ChangedPaths) != 1 ||
+				update.ChangedPaths[0] != "/configs/a.json" {
+				t.Fatalf("update: %+v", update)
+			}
+			return
+		case <-deadline:
+			t.Fatal("no update delivered")
+		}
+	}
+}
+
+func TestGetAndWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			// The long poll must not influence the synchronous result.
+			time.Sleep(200 * time.Millisecond)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":5}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, latest, err := client.GetAndWatch(context.Background(), "foo", "bar",
+		&Query{Path: "/a.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if latest.Revision != 5 {
+		t.Errorf("revision: %v, want 5", latest.Revision)
+	}
+	value, ok := latest.Value.(map[string]interface{})
+	if !ok || value["a"] != float64(1) {
+		t.Errorf("value: %v", latest.Value)
+	}
+	// The watcher is seeded: Latest is available without awaiting.
+	if seeded := watcher.Latest(); seeded.Err != nil || seeded.Revision != 5 {
+		t.Errorf("seeded latest: %+v", seeded)
+	}
+}
+
+func TestFileWatcherJSONPathProjection(t *testing.T) {
+	var jsonPathParams []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == "" {
+				jsonPathParams = r.URL.Query()["jsonpath"]
+				fmt.Fprint(w, `{"path":"/a.json","type":"JSON","revision":1,`+
+					`"content":{"x":true}}`)
+				return
+			}
+			switch r.Header.Get("If-None-Match") {
+			case "1":
+				// A sibling field changed: the projection is identical.
+				fmt.Fprint(w, `{"revision":2,"entry":{"path":"/a.json",`+
+					`"type":"JSON","revision":2,"content":{"x":true}}}`)
+			case "2":
+				fmt.Fprint(w, `{"revision":3,"entry":{"path":"/a.json",`+
+					`"type":"JSON","revision":3,"content":{"x":false}}}`)
+			default:
+				w.WriteHeader(http.StatusNotModified)
+			}
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &Query{Path: "/a.json", Type: JSONPath, Expressions: []string{"$.flags"}}
+	watcher, err := client.FileWatcher("foo", "bar", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	type notification struct {
+		revision int
+		value    interface{}
+	}
+	notifications := make(chan notification, 8)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		notifications <- notification{revision, value}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []notification
+	deadline := time.After(5 * time.Second)
+	for len(got) < 2 {
+		select {
+		case n := <-notifications:
+			got = append(got, n)
+		case <-deadline:
+			t.Fatalf("notifications so far: %+v", got)
+		}
+	}
+
+	if len(jsonPathParams) != 1 || jsonPathParams[0] != "$.flags" {
+		t.Errorf("jsonpath params: %v", jsonPathParams)
+	}
+	if got[0].revision != 1 || !reflect.DeepEqual(got[0].value, map[string]interface{}{"x": true}) {
+		t.Errorf("first notification: %+v", got[0])
+	}
+	// Revision 2 left the projection untouched and must be skipped.
+	if got[1].revision != 3 || !reflect.DeepEqual(got[1].value, map[string]interface{}{"x": false}) {
+		t.Errorf("second notification: %+v", got[1])
+	}
+	if latest := watcher.Latest(); latest.Revision < 3 {
+		t.Errorf("latest revision: %v, want the watcher to advance past 3", latest.Revision)
+	}
+}
+
+func TestWatcherRepollsPromptlyAfterNotModified(t *testing.T) {
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == "" {
+				fmt.Fprint(w, `{"revision":1,"path":"/a.json","type":"JSON","content":{"a":1}}`)
+				return
+			}
+			polls++
+			if polls <= 2 {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, `{"revision":2,"entry":{"revision":2,"path":"/a.json","type":"JSON","content":{"a":2}}}`)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.FileWatcher("foo", "bar", &Query{Path: "/a.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	revisions := make(chan int, 4)
+	watcher.Watch(func(revision int, value interface{}) { revisions <- revision })
+
+	// Two long polls time out before the change arrives. With the former
+	// error backoff the change would take several seconds to show up; without
+	// backoff it arrives almost immediately.
+	start := time.Now()
+	deadline := time.After(5 * time.Second)
+	for got := 0; got != 2; {
+		select {
+		case got = <-revisions:
+		case <-deadline:
+			t.Fatal("timed out waiting for revision 2")
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("change took %v to arrive, want an immediate re-poll after 304", elapsed)
+	}
+	if polls < 3 {
+		t.Errorf("polls: %v, want at least 3", polls)
+	}
+}
+
+func TestWatchMissingRepositoryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"exception":"com.linecorp.centraldogma.common.RepositoryNotFoundException",`+
+			`"message":"repository not found: gone"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &Query{Path: "/a.json", Type: Identity}
+	_, err = client.WatchFile(context.Background(), "foo", "gone", query, 1, time.Second)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err: %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("status code: %v, want 404", apiErr.StatusCode)
+	}
+	if apiErr.Message != "repository not found: gone" {
+		t.Errorf("message: %q, want the server message", apiErr.Message)
+	}
+
+	// The same typed error reaches an OnError listener of a watcher.
+	watchErrs := make(chan error, 8)
+	watcher, err := client.NewWatcherBuilder("foo", "gone").
+		ForFile(query).
+		OnError(func(err error) { watchErrs <- err }).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	select {
+	case err := <-watchErrs:
+		apiErr = nil
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+			t.Errorf("watch error: %v, want a 404 *APIError", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the watch error")
+	}
+}
+
+func TestRepoWatcherOnPath(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/revision/-1",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"revision":1}`)
+		})
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/",
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == "" {
+				// Entry fetches for the changed paths.
+				switch r.URL.Path {
+				case "/api/v1/projects/foo/repos/bar/contents/a/x.json":
+					fmt.Fprint(w, `{"path":"/a/x.json","type":"JSON","content":{"x":1},"revision":2}`)
+				case "/api/v1/projects/foo/repos/bar/contents/b/y.json":
+					fmt.Fprint(w, `{"path":"/b/y.json","type":"JSON","content":{"y":1},"revision":2}`)
+				default:
+					t.Errorf("unexpected entry fetch: %v", r.URL.Path)
+				}
+				return
+			}
+			if r.Header.Get("If-None-Match") == "1" {
+				fmt.Fprint(w, `{"revision":2,"changedPaths":["/a/x.json","/b/y.json"]}`)
+				return
+			}
+			w.WriteHeader(http.StatusNotModified)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.RepoWatcher("foo", "bar", "/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	type handled struct {
+		revision int
+		path     string
+	}
+	aEntries := make(chan handled, 8)
+	if err := watcher.OnPath("/a/**", func(revision int, entry *Entry) {
+		aEntries <- handled{revision: revision, path: entry.Path}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bEntries := make(chan handled, 8)
+	if err := watcher.OnPath("/b/**", func(revision int, entry *Entry) {
+		bEntries <- handled{revision: revision, path: entry.Path}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-aEntries:
+		if got.revision != 2 || got.path != "/a/x.json" {
+			t.Errorf("handled: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the /a/** handler was not invoked")
+	}
+	select {
+	case got := <-bEntries:
+		if got.path != "/b/y.json" {
+			t.Errorf("handled: %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the /b/** handler was not invoked")
+	}
+	// Each handler only sees the paths under its own pattern.
+	select {
+	case got := <-aEntries:
+		t.Errorf("the /a/** handler also saw %+v", got)
+	default:
+	}
+}
+
+func TestOnPathRejectsFileWatcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":1}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.FileWatcher("foo", "bar", &Query{Path: "/a.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.OnPath("/**", func(int, *Entry) {}); err == nil {
+		t.Error("OnPath succeeded on a file watcher")
+	}
+}
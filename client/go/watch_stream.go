@@ -0,0 +1,96 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WatchStream consumes a streaming watch endpoint: instead of one long-poll
+// request per change, the server keeps a single connection open and emits
+// one JSON commit per line as changes happen. Each commit is delivered on
+// the returned channel; the connection is reestablished when it drops and
+// the channel is closed when the context is canceled. Servers without the
+// streaming variant answer with a non-2xx status, which is treated like a
+// disconnect.
+func (c *Client) WatchStream(ctx context.Context, projectName, repoName, pathPattern string) <-chan *Commit {
+	u := contentsURL(projectName, repoName, normalizePathPattern(pathPattern), nil)
+	commits := make(chan *Commit)
+	go func() {
+		defer close(commits)
+		for {
+			c.streamOnce(ctx, u, commits)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delayOnSuccess):
+			}
+		}
+	}()
+	return commits
+}
+
+// streamOnce consumes a single streaming connection until it drops or the
+// context is canceled.
+func (c *Client) streamOnce(ctx context.Context, u string, commits chan<- *Commit) {
+	req, err := c.newRequest(http.MethodGet, u, nil)
+	if err != nil {
+		logger.Printf("failed to build the streaming watch request for %v: %v", u, err)
+		return
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+	req = req.WithContext(ctx)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Printf("streaming watch of %v failed: %v", u, err)
+		}
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		logger.Printf("streaming watch of %v failed: status: %v", u, res.StatusCode)
+		io.Copy(io.Discard, res.Body)
+		return
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		commit := new(Commit)
+		if err := json.Unmarshal(line, commit); err != nil {
+			logger.Printf("streaming watch of %v delivered a malformed event: %v", u, err)
+			continue
+		}
+		select {
+		case commits <- commit:
+		case <-ctx.Done():
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		logger.Printf("streaming watch of %v disconnected: %v", u, err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchStream(t *testing.T) {
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			t.Error("response writer is not flushable")
+			return
+		}
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			// Two events flushed over one connection, then a disconnect.
+			fmt.Fprint(w, "{\"revision\":2}\n")
+			flusher.Flush()
+			fmt.Fprint(w, "{\"revision\":3}\n")
+			flusher.Flush()
+		default:
+			// The reconnected stream delivers the next event.
+			fmt.Fprint(w, "{\"revision\":4}\n")
+			flusher.Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	commits := client.WatchStream(ctx, "foo", "bar", "/**")
+
+	for _, want := range []int{2, 3, 4} {
+		select {
+		case commit := <-commits:
+			if commit.Revision != want {
+				t.Fatalf("revision: %v, want %v", commit.Revision, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for revision %v", want)
+		}
+	}
+	if got := atomic.LoadInt32(&connections); got != 2 {
+		t.Errorf("connections: %v, want the first two events on one connection", got)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-commits:
+		if ok {
+			t.Error("received an event after cancellation")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the channel was not closed after cancellation")
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.linecorp.com/centraldogma/internal/pathutil"
+)
+
+// logger is the package-level logger. It discards everything by default; the
+// CLI or an application can point it at a real sink with SetLogOutput to see
+// request and watch activity.
+var logger = log.New(io.Discard, "centraldogma: ", log.LstdFlags)
+
+// SetLogOutput directs the diagnostic output of the package — request URLs,
+// watch retries and the like — to the specified writer. Pass io.Discard to
+// silence it again.
+func SetLogOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
+
+const (
+	watcherInitial int32 = iota
+	watcherStarted
+	watcherStopped
+)
+
+const (
+	delayOnSuccess = 1 * time.Second
+	minInterval    = delayOnSuccess * 2
+	maxInterval    = 1 * time.Minute
+	jitterRate     = 0.2
+)
+
+// ErrLatestNotSet is returned by Watcher.Latest when the watcher has not yet
+// retrieved the initial value.
+var ErrLatestNotSet = errors.New("centraldogma: latest value is not set yet")
+
+// Latest is a snapshot of the most recently retrieved value of a watched
+// file or repository.
+type Latest struct {
+	Revision int
+	Value    interface{}
+	Err      error
+}
+
+// Watcher watches a file or a repository and notifies the registered
+// listeners when it is changed. A Watcher is safe for concurrent use.
+type Watcher struct {
+	state int32 // accessed atomically
+
+	initialValueCh   chan struct{}
+	initialValueOnce sync.Once
+	closedCh         chan struct{}
+	closedOnce       sync.Once
+	latest           atomic.Value // *Latest
+	lastUpdatedAt    atomic.Value // time.Time
+
+	updateListeners []func(revision int, value interface{})
+	listenersMutex  sync.Mutex
+
+	projectName string
+	repoName    string
+	pathPattern string
+
+	// initialValueFunc fetches the current value once before the first
+	// long-poll so that AwaitInitialValue does not block until a change.
+	initialValueFunc func(ctx context.Context) (*Latest, error)
+	// doWatchFunc performs one watch long-poll since the given revision.
+	doWatchFunc func(ctx context.Context, lastKnownRevision int) (*WatchResult, error)
+	// convertingResultFunc converts a watch result into the value that is
+	// delivered to the listeners.
+	convertingResultFunc func(result *WatchResult) *Latest
+	// transform, when non-nil, is applied to the converted value before it
+	// is stored and delivered.
+	transform func(value interface{}) interface{}
+	// onError, when non-nil, is invoked whenever a poll fails.
+	onError func(err error)
+	// suppressUnchanged skips notifying the listeners when the converted
+	// value is deep-equal to the previous one, while still advancing the
+	// revision.
+	suppressUnchanged bool
+	// staleAfter and onStale, when set, report when no poll has confirmed
+	// the value fresh for staleAfter.
+	staleAfter time.Duration
+	onStale    func(elapsed time.Duration)
+	// getEntry fetches a changed entry at a revision. It is only set for
+	// repository watchers, which route entries to OnPath handlers with it.
+	getEntry func(ctx context.Context, revision int, path string) (*Entry, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// FileWatcher returns a started Watcher which watches the file matched by
+// the given query. With a JSONPath query only the projected value is
+// delivered, and listeners are not notified when a change elsewhere in the
+// file leaves the projection untouched.
+func (c *Client) FileWatcher(projectName, repoName string, query *Query) (*Watcher, error) {
+	if query == nil {
+		return nil, fmt.Errorf("centraldogma: query should not be nil")
+	}
+	w := newFileWatcher(c, projectName, repoName, query, DefaultWatchTimeout)
+	w.start()
+	return w, nil
+}
+
+// GetAndWatch fetches the current value of the file matched by the given
+// query synchronously, seeds a watcher with it and returns both, so that the
+// caller has a usable value immediately and no initial-value race to handle.
+func (c *Client) GetAndWatch(ctx context.Context, projectName, repoName string, query *Query) (*Watcher, Latest, error) {
+	if query == nil {
+		return nil, Latest{}, fmt.Errorf("centraldogma: query should not be nil")
+	}
+	w := newFileWatcher(c, projectName, repoName, query, DefaultWatchTimeout)
+	initial, err := w.initialValueFunc(ctx)
+	if err != nil {
+		return nil, Latest{}, err
+	}
+	w.notify(initial)
+	w.start()
+	return w, *initial, nil
+}
+
+// RepoWatcher returns a started Watcher which watches the files matched by
+// the given path pattern. The value delivered to the listeners is a
+// RepoUpdate carrying the new revision and the changed entry paths.
+//
+// When the pattern is not a pattern at all but the path of a single JSON
+// file, the caller almost certainly wanted the content of that file rather
+// than a stream of revision numbers, so the watcher falls back to file
+// semantics as if FileWatcher was called with an identity query.
+func (c *Client) RepoWatcher(projectName, repoName, pathPattern string) (*Watcher, error) {
+	var w *Watcher
+	if isSingleJSONFile(pathPattern) {
+		w = newFileWatcher(c, projectName, repoName,
+			&Query{Path: normalizePathPattern(pathPattern), Type: Identity}, DefaultWatchTimeout)
+	} else {
+		w = newRepoWatcher(c, projectName, repoName, pathPattern, DefaultWatchTimeout)
+	}
+	w.start()
+	return w, nil
+}
+
+// isSingleJSONFile reports whether the given path pattern is the path of a
+// single JSON file rather than a pattern matching a set of files.
+func isSingleJSONFile(pathPattern string) bool {
+	return !strings.ContainsAny(pathPattern, "*,") &&
+		strings.HasSuffix(strings.ToLower(pathPattern), ".json")
+}
+
+func newWatcher(projectName, repoName, pathPattern string) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Watcher{
+		initialValueCh: make(chan struct{}),
+		closedCh:       make(chan struct{}),
+		projectName:    projectName,
+		repoName:       repoName,
+		pathPattern:    pathPattern,
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+func newFileWatcher(c *Client, projectName, repoName string, query *Query, timeout time.Duration) *Watcher {
+	w := newWatcher(projectName, repoName, query.Path)
+	w.initialValueFunc = func(ctx context.Context) (*Latest, error) {
+		entry, err := c.content.getFile(ctx, projectName, repoName, "-1", query)
+		if err != nil {
+			return nil, err
+		}
+		value, err := fileWatchValue(entry)
+		if err != nil {
+			return nil, err
+		}
+		return &Latest{Revision: entry.Revision, Value: value}, nil
+	}
+	w.doWatchFunc = func(ctx context.Context, lastKnownRevision int) (*WatchResult, error) {
+		return c.watch.watchFile(ctx, projectName, repoName, query, lastKnownRevision, timeout)
+	}
+	w.convertingResultFunc = func(result *WatchResult) *Latest {
+		value, err := fileWatchValue(result.Entry)
+		return &Latest{Revision: result.Revision, Value: value, Err: err}
+	}
+	// A projection can stay identical while the rest of the file changes;
+	// re-notifying listeners with the same value would defeat its purpose.
+	w.suppressUnchanged = query.Type == JSONPath
+	return w
+}
+
+// RepoUpdate is the value delivered to the listeners of a repository
+// watcher. ChangedPaths is empty for the initial value and when the server
+// does not report the changed entries.
+type RepoUpdate struct {
+	Revision     int
+	ChangedPaths []string
+}
+
+func newRepoWatcher(c *Client, projectName, repoName, pathPattern string, timeout time.Duration) *Watcher {
+	pathPattern = normalizePathPattern(pathPattern)
+	w := newWatcher(projectName, repoName, pathPattern)
+	w.initialValueFunc = func(ctx context.Context) (*Latest, error) {
+		revision, err := c.repo.normalizeRevision(ctx, projectName, repoName, "-1")
+		if err != nil {
+			return nil, err
+		}
+		return &Latest{Revision: revision, Value: RepoUpdate{Revision: revision}}, nil
+	}
+	w.doWatchFunc = func(ctx context.Context, lastKnownRevision int) (*WatchResult, error) {
+		return c.watch.watchRepo(ctx, projectName, repoName, pathPattern, lastKnownRevision, timeout)
+	}
+	w.convertingResultFunc = func(result *WatchResult) *Latest {
+		update := RepoUpdate{Revision: result.Revision, ChangedPaths: result.ChangedPaths}
+		return &Latest{Revision: result.Revision, Value: update}
+	}
+	w.getEntry = func(ctx context.Context, revision int, path string) (*Entry, error) {
+		return c.content.getFile(ctx, projectName, repoName, strconv.Itoa(revision),
+			&Query{Path: path, Type: Identity})
+	}
+	return w
+}
+
+// OnPath registers a handler which is invoked with each changed entry under
+// the given sub-pattern whenever the watched repository advances, so that a
+// dispatcher watching "/**" does not have to register one listener and route
+// by path itself. The entry is fetched at the new revision; a path that can
+// no longer be fetched — typically a removal — is skipped. OnPath is only
+// supported on a repository watcher.
+func (w *Watcher) OnPath(pattern string, handler func(revision int, entry *Entry)) error {
+	if w.getEntry == nil {
+		return fmt.Errorf("centraldogma: OnPath is only supported on a repository watcher")
+	}
+	if handler == nil {
+		return fmt.Errorf("centraldogma: handler should not be nil")
+	}
+	re, err := pathutil.CompilePathPattern(pattern)
+	if err != nil {
+		return err
+	}
+	return w.Watch(func(revision int, value interface{}) {
+		update, ok := value.(RepoUpdate)
+		if !ok {
+			return
+		}
+		for _, path := range update.ChangedPaths {
+			if !re.MatchString(path) {
+				continue
+			}
+			entry, err := w.getEntry(w.ctx, revision, path)
+			if err != nil {
+				logger.Printf("failed to fetch the changed entry %v at revision %v: %v",
+					path, revision, err)
+				continue
+			}
+			handler(revision, entry)
+		}
+	})
+}
+
+// fileWatchValue converts the content of the specified entry into the value
+// delivered to the listeners: decoded JSON for a JSON entry and a string for
+// a text entry.
+func fileWatchValue(entry *Entry) (interface{}, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("centraldogma: watch result has no entry")
+	}
+	switch entry.Type {
+	case JSON:
+		var value interface{}
+		if err := json.Unmarshal(entry.Content, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case Text:
+		return string(entry.Content), nil
+	default:
+		return nil, fmt.Errorf("centraldogma: unexpected entry type: %v", entry.Type)
+	}
+}
+
+// AwaitInitialValue awaits until the initial value is retrieved and returns
+// it.
+func (w *Watcher) AwaitInitialValue() Latest {
+	<-w.initialValueCh
+	return w.Latest()
+}
+
+// AwaitInitialValueWith awaits up to the specified timeout until the initial
+// value is retrieved and returns it. When the timeout elapses first, the
+// returned Latest carries a timeout error.
+func (w *Watcher) AwaitInitialValueWith(timeout time.Duration) Latest {
+	select {
+	case <-w.initialValueCh:
+		return w.Latest()
+	case <-time.After(timeout):
+		return Latest{Err: fmt.Errorf(
+			"centraldogma: failed to get the initial value within %v", timeout)}
+	}
+}
+
+// Latest returns the latest revision and value of the watched target. It
+// returns a Latest whose Err is ErrLatestNotSet when the initial value is
+// not retrieved yet.
+func (w *Watcher) Latest() Latest {
+	latest, ok := w.latest.Load().(*Latest)
+	if !ok || latest == nil {
+		return Latest{Err: ErrLatestNotSet}
+	}
+	return *latest
+}
+
+// LastUpdatedAt returns when the watcher last confirmed its value fresh:
+// the time the latest value was stored, or the time a long poll last
+// completed without a change. Monitoring can compare it against the clock to
+// alert on a watch that is stuck or reconnecting. It returns the zero time
+// before the initial value is retrieved.
+func (w *Watcher) LastUpdatedAt() time.Time {
+	updated, ok := w.lastUpdatedAt.Load().(time.Time)
+	if !ok {
+		return time.Time{}
+	}
+	return updated
+}
+
+// Watch registers a listener which is invoked with the revision and the
+// value whenever the watched target is changed.
+func (w *Watcher) Watch(listener func(revision int, value interface{})) error {
+	if listener == nil {
+		return fmt.Errorf("centraldogma: listener should not be nil")
+	}
+	if atomic.LoadInt32(&w.state) == watcherStopped {
+		return fmt.Errorf("centraldogma: watcher is closed")
+	}
+
+	w.listenersMutex.Lock()
+	w.updateListeners = append(w.updateListeners, listener)
+	w.listenersMutex.Unlock()
+	return nil
+}
+
+// Close stops watching. It is safe to call Close more than once.
+func (w *Watcher) Close() {
+	w.markStopped()
+	w.cancel()
+}
+
+// Closed returns a channel which is closed when the watcher has stopped,
+// either by Close or by a fatal cancellation of its context, so that
+// goroutines can select on the watcher lifecycle.
+func (w *Watcher) Closed() <-chan struct{} {
+	return w.closedCh
+}
+
+// markStopped transitions the watcher to the stopped state and closes the
+// Closed channel exactly once.
+func (w *Watcher) markStopped() {
+	atomic.StoreInt32(&w.state, watcherStopped)
+	w.closedOnce.Do(func() { close(w.closedCh) })
+}
+
+func (w *Watcher) isStopped() bool {
+	return atomic.LoadInt32(&w.state) == watcherStopped
+}
+
+func (w *Watcher) start() {
+	if atomic.CompareAndSwapInt32(&w.state, watcherInitial, watcherStarted) {
+		go w.watchLoop()
+		if w.staleAfter > 0 && w.onStale != nil {
+			go w.staleLoop()
+		}
+	}
+}
+
+// staleLoop checks once per staleAfter whether any poll confirmed the value
+// fresh in the meantime and invokes onStale with the elapsed time when none
+// did. It keeps firing once per interval for as long as the watch stays
+// stale.
+func (w *Watcher) staleLoop() {
+	ticker := time.NewTicker(w.staleAfter)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.closedCh:
+			return
+		case <-ticker.C:
+			updated := w.LastUpdatedAt()
+			if elapsed := time.Since(updated); elapsed >= w.staleAfter {
+				w.onStale(elapsed)
+			}
+		}
+	}
+}
+
+func (w *Watcher) watchLoop() {
+	defer w.markStopped()
+	attempts := 0
+	for !w.isStopped() {
+		latest, ok := w.latest.Load().(*Latest)
+		if !ok || latest == nil {
+			initial, err := w.initialValueFunc(w.ctx)
+			if err != nil {
+				logger.Printf("failed to get the initial value of %v/%v%v: %v",
+					w.projectName, w.repoName, w.pathPattern, err)
+				if w.onError != nil {
+					w.onError(err)
+				}
+				if !w.delay(attempts) {
+					return
+				}
+				attempts++
+				continue
+			}
+			attempts = 0
+			w.notify(initial)
+			continue
+		}
+
+		result, err := w.doWatchFunc(w.ctx, latest.Revision)
+		if err == ErrWatchTimeout {
+			// Nothing changed within the wait window. That is the normal
+			// steady state of a long poll, so re-poll immediately instead of
+			// backing off as if the server had failed. The poll still
+			// confirmed the value fresh.
+			w.lastUpdatedAt.Store(time.Now())
+			attempts = 0
+			continue
+		}
+		if err != nil {
+			logger.Printf("failed to watch %v/%v%v: %v",
+				w.projectName, w.repoName, w.pathPattern, err)
+			if w.onError != nil {
+				w.onError(err)
+			}
+			if !w.delay(attempts) {
+				return
+			}
+			attempts++
+			continue
+		}
+		attempts = 0
+		w.notify(w.convertingResultFunc(result))
+	}
+}
+
+// notify stores the specified latest value and invokes the registered
+// listeners.
+func (w *Watcher) notify(latest *Latest) {
+	if latest.Err == nil && w.transform != nil {
+		latest.Value = w.transform(latest.Value)
+	}
+	previous, _ := w.latest.Load().(*Latest)
+	w.latest.Store(latest)
+	w.lastUpdatedAt.Store(time.Now())
+	w.initialValueOnce.Do(func() { close(w.initialValueCh) })
+	if latest.Err != nil {
+		return
+	}
+	if w.suppressUnchanged && previous != nil && previous.Err == nil &&
+		reflect.DeepEqual(previous.Value, latest.Value) {
+		return
+	}
+
+	w.listenersMutex.Lock()
+	listeners := make([]func(int, interface{}), len(w.updateListeners))
+	copy(listeners, w.updateListeners)
+	w.listenersMutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(latest.Revision, latest.Value)
+	}
+}
+
+// delay sleeps for an exponentially increasing interval with jitter. It
+// returns false when the watcher is closed while sleeping.
+func (w *Watcher) delay(attempts int) bool {
+	var interval time.Duration
+	if attempts == 0 {
+		interval = delayOnSuccess
+	} else {
+		interval = nextDelay(attempts)
+	}
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(interval):
+		return true
+	}
+}
+
+func nextDelay(attempts int) time.Duration {
+	interval := minInterval << uint(attempts-1)
+	if interval <= 0 || interval > maxInterval {
+		interval = maxInterval
+	}
+	jitter := time.Duration((rand.Float64()*2 - 1) * jitterRate * float64(interval))
+	return interval + jitter
+}
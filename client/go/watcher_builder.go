@@ -0,0 +1,152 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"fmt"
+	"time"
+)
+
+// WatcherBuilder configures a Watcher fluently. FileWatcher and RepoWatcher
+// remain the shortest way to get a watcher with the defaults; the builder is
+// for callers who need a custom timeout, a value transform or an error
+// callback:
+//
+//	w, err := client.NewWatcherBuilder("foo", "bar").
+//		ForFile(&centraldogma.Query{Path: "/a.json", Type: centraldogma.Identity}).
+//		WithTimeout(30 * time.Second).
+//		Build()
+type WatcherBuilder struct {
+	client      *Client
+	projectName string
+	repoName    string
+
+	query       *Query
+	pathPattern string
+
+	timeout           time.Duration
+	transform         func(value interface{}) interface{}
+	onError           func(err error)
+	suppressUnchanged bool
+	startFrom         *int
+	staleAfter        time.Duration
+	onStale           func(elapsed time.Duration)
+}
+
+// NewWatcherBuilder returns a builder which configures a Watcher for the
+// specified repository.
+func (c *Client) NewWatcherBuilder(projectName, repoName string) *WatcherBuilder {
+	return &WatcherBuilder{
+		client:      c,
+		projectName: projectName,
+		repoName:    repoName,
+		timeout:     DefaultWatchTimeout,
+	}
+}
+
+// ForFile makes the built Watcher watch the file matched by the given query.
+func (b *WatcherBuilder) ForFile(query *Query) *WatcherBuilder {
+	b.query = query
+	return b
+}
+
+// ForRepository makes the built Watcher watch the files matched by the given
+// path pattern.
+func (b *WatcherBuilder) ForRepository(pathPattern string) *WatcherBuilder {
+	b.pathPattern = pathPattern
+	return b
+}
+
+// WithTimeout sets the timeout of each watch long-poll. It defaults to
+// DefaultWatchTimeout.
+func (b *WatcherBuilder) WithTimeout(timeout time.Duration) *WatcherBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// WithTransform applies the specified function to each value before it is
+// stored and delivered to the listeners.
+func (b *WatcherBuilder) WithTransform(transform func(value interface{}) interface{}) *WatcherBuilder {
+	b.transform = transform
+	return b
+}
+
+// OnError registers a callback which is invoked whenever a poll fails. The
+// watcher keeps retrying with backoff regardless.
+func (b *WatcherBuilder) OnError(onError func(err error)) *WatcherBuilder {
+	b.onError = onError
+	return b
+}
+
+// OnStale registers a callback which is invoked with the elapsed time when
+// no poll has confirmed the watched value fresh for staleAfter, so that a
+// stuck or endlessly reconnecting watch can be alerted on. The callback
+// keeps firing once per staleAfter for as long as the watch stays stale.
+func (b *WatcherBuilder) OnStale(staleAfter time.Duration, onStale func(elapsed time.Duration)) *WatcherBuilder {
+	b.staleAfter = staleAfter
+	b.onStale = onStale
+	return b
+}
+
+// SuppressUnchanged makes the built Watcher skip notifying the listeners
+// when an update carries a value deep-equal to the previous one, e.g. a
+// no-op commit or, under a repository watch, a change to an unwatched
+// sibling. The watched revision still advances. The comparison runs after
+// the transform of WithTransform, so a transform can also be used to narrow
+// what counts as a change.
+func (b *WatcherBuilder) SuppressUnchanged() *WatcherBuilder {
+	b.suppressUnchanged = true
+	return b
+}
+
+// StartFrom seeds the last-known revision of the built Watcher instead of
+// fetching the current value first, so that the first notification is the
+// next change after the given revision — even one that already happened.
+// The initial value is then only available once such a change is seen.
+func (b *WatcherBuilder) StartFrom(revision int) *WatcherBuilder {
+	b.startFrom = &revision
+	return b
+}
+
+// Build returns a started Watcher with the configured options. Exactly one
+// of ForFile and ForRepository must have been called.
+func (b *WatcherBuilder) Build() (*Watcher, error) {
+	if (b.query == nil) == (b.pathPattern == "") {
+		return nil, fmt.Errorf(
+			"centraldogma: exactly one of ForFile and ForRepository should be specified")
+	}
+	if b.timeout <= 0 {
+		return nil, fmt.Errorf("centraldogma: timeout should be positive: %v", b.timeout)
+	}
+
+	var w *Watcher
+	if b.query != nil {
+		w = newFileWatcher(b.client, b.projectName, b.repoName, b.query, b.timeout)
+	} else {
+		w = newRepoWatcher(b.client, b.projectName, b.repoName, b.pathPattern, b.timeout)
+	}
+	w.transform = b.transform
+	w.onError = b.onError
+	w.staleAfter = b.staleAfter
+	w.onStale = b.onStale
+	if b.suppressUnchanged {
+		w.suppressUnchanged = true
+	}
+	if b.startFrom != nil {
+		w.latest.Store(&Latest{Revision: *b.startFrom})
+	}
+	w.start()
+	return w, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 LINE Corporation
+//
+// LINE Corporation licenses this file to you under the Apache License,
+// version 2.0 (the "License"); you may not use this file except in compliance
+// with the License. You may obtain a copy of the License at:
+//
+//   https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package centraldogma
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatcherBuilderFileWatcher(t *testing.T) {
+	var mutex sync.Mutex
+	var preferHeaders []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if prefer := r.Header.Get("Prefer"); prefer != "" {
+			mutex.Lock()
+			preferHeaders = append(preferHeaders, prefer)
+			mutex.Unlock()
+		}
+		switch r.Header.Get("If-None-Match") {
+		case "":
+			fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"name":"x"},"revision":10}`)
+		case "10":
+			fmt.Fprint(w, `{"revision":11,`+
+				`"entry":{"path":"/a.json","type":"JSON","content":{"name":"y"},"revision":11}}`)
+		default:
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.NewWatcherBuilder("foo", "bar").
+		ForFile(&Query{Path: "/a.json", Type: Identity}).
+		WithTimeout(5 * time.Second).
+		WithTransform(func(value interface{}) interface{} {
+			return value.(map[string]interface{})["name"]
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	values := make(chan interface{}, 8)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		values <- value
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if latest := watcher.AwaitInitialValueWith(5 * time.Second); latest.Err != nil {
+		t.Fatal(latest.Err)
+	} else if latest.Value != "x" {
+		t.Errorf("initial value: %v, want the transformed %q", latest.Value, "x")
+	}
+	// The listener observes the initial value first and then the update.
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case value := <-values:
+			if value == "y" {
+				goto updated
+			}
+			if value != "x" {
+				t.Fatalf("value: %v, want the transformed %q or %q", value, "x", "y")
+			}
+		case <-deadline:
+			t.Fatal("no update delivered")
+		}
+	}
+updated:
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(preferHeaders) == 0 {
+		t.Fatal("no watch request observed")
+	}
+	if preferHeaders[0] != "wait=5" {
+		t.Errorf("Prefer: %q, want %q", preferHeaders[0], "wait=5")
+	}
+}
+
+func TestWatcherBuilderOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"message":"boom"}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 8)
+	watcher, err := client.NewWatcherBuilder("foo", "bar").
+		ForRepository("/**").
+		OnError(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	select {
+	case err := <-errs:
+		apiError, ok := err.(*APIError)
+		if !ok {
+			t.Fatalf("err: %T (%v), want *APIError", err, err)
+		}
+		if apiError.StatusCode != http.StatusInternalServerError {
+			t.Errorf("status code: %v, want 500", apiError.StatusCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error callback not invoked")
+	}
+}
+
+func TestWatcherBuilderRequiresTarget(t *testing.T) {
+	client, err := NewClientWithToken("localhost:36462", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewWatcherBuilder("foo", "bar").Build(); err == nil {
+		t.Error("Build without a target did not fail")
+	}
+	if _, err := client.NewWatcherBuilder("foo", "bar").
+		ForFile(&Query{Path: "/a.json", Type: Identity}).
+		ForRepository("/**").
+		Build(); err == nil {
+		t.Error("Build with both targets did not fail")
+	}
+}
+
+func TestWatcherBuilderSuppressUnchanged(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.Header.Get("If-None-Match") {
+			case "":
+				fmt.Fprint(w, `{"path":"/a.json","type":"JSON","revision":1,"content":{"k":1}}`)
+			case "1":
+				// A no-op commit: same content at a new revision.
+				fmt.Fprint(w, `{"revision":2,"entry":{"path":"/a.json",`+
+					`"type":"JSON","revision":2,"content":{"k":1}}}`)
+			case "2":
+				fmt.Fprint(w, `{"revision":3,"entry":{"path":"/a.json",`+
+					`"type":"JSON","revision":3,"content":{"k":2}}}`)
+			default:
+				w.WriteHeader(http.StatusNotModified)
+			}
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.NewWatcherBuilder("foo", "bar").
+		ForFile(&Query{Path: "/a.json", Type: Identity}).
+		SuppressUnchanged().
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	revisions := make(chan int, 8)
+	if err := watcher.Watch(func(revision int, value interface{}) {
+		revisions <- revision
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	awaitRevision(t, revisions, 1)
+	// Revision 2 carried identical content and must not notify; the next
+	// notification is revision 3.
+	awaitRevision(t, revisions, 3)
+}
+
+func TestWatcherBuilderStartFrom(t *testing.T) {
+	polled := make(chan string, 4)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/foo/repos/bar/contents/a.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			rev := r.Header.Get("If-None-Match")
+			if rev == "" {
+				t.Error("initial value was fetched although StartFrom was set")
+				fmt.Fprint(w, `{"revision":5,"path":"/a.json","type":"JSON","content":{"a":5}}`)
+				return
+			}
+			polled <- rev
+			fmt.Fprint(w, `{"revision":11,"entry":{"revision":11,"path":"/a.json","type":"JSON","content":{"a":11}}}`)
+		})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher, err := client.NewWatcherBuilder("foo", "bar").
+		ForFile(&Query{Path: "/a.json", Type: Identity}).
+		StartFrom(10).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	select {
+	case rev := <-polled:
+		if rev != "10" {
+			t.Errorf("first poll revision: %v, want 10", rev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first poll")
+	}
+
+	latest := watcher.AwaitInitialValueWith(5 * time.Second)
+	if latest.Err != nil {
+		t.Fatal(latest.Err)
+	}
+	if latest.Revision != 11 {
+		t.Errorf("revision: %v, want 11", latest.Revision)
+	}
+}
+
+func TestWatcherLastUpdatedAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("If-None-Match") {
+		case "":
+			fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":1}`)
+		case "1":
+			fmt.Fprint(w, `{"revision":2,"entry":{"path":"/a.json","type":"JSON","content":{"a":2}}}`)
+		default:
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := client.FileWatcher("foo", "bar", &Query{Path: "/a.json", Type: Identity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	revisions := make(chan int, 8)
+	watcher.Watch(func(revision int, value interface{}) { revisions <- revision })
+
+	if updated := watcher.LastUpdatedAt(); !updated.IsZero() {
+		t.Errorf("last updated: %v, want the zero time before the initial value", updated)
+	}
+	if latest := watcher.AwaitInitialValueWith(5 * time.Second); latest.Err != nil {
+		t.Fatal(latest.Err)
+	}
+	first := watcher.LastUpdatedAt()
+	if first.IsZero() {
+		t.Fatal("last updated is still the zero time after the initial value")
+	}
+
+	// The listener also observes the initial value; drain until the update.
+	deadline := time.After(5 * time.Second)
+	for got := 0; got != 2; {
+		select {
+		case got = <-revisions:
+		case <-deadline:
+			t.Fatal("timed out waiting for revision 2")
+		}
+	}
+	if second := watcher.LastUpdatedAt(); !second.After(first) {
+		t.Errorf("last updated did not advance: %v then %v", first, second)
+	}
+}
+
+func TestWatcherOnStale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "" {
+			fmt.Fprint(w, `{"path":"/a.json","type":"JSON","content":{"a":1},"revision":1}`)
+			return
+		}
+		// The watch hangs, so no poll ever confirms the value fresh.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client, err := NewClientWithToken(server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := make(chan time.Duration, 8)
+	watcher, err := client.NewWatcherBuilder("foo", "bar").
+		ForFile(&Query{Path: "/a.json", Type: Identity}).
+		OnStale(100*time.Millisecond, func(elapsed time.Duration) { stale <- elapsed }).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	select {
+	case elapsed := <-stale:
+		if elapsed < 100*time.Millisecond {
+			t.Errorf("elapsed: %v, want at least the stale interval", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stale callback did not fire")
+	}
+}